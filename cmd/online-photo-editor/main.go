@@ -5,17 +5,31 @@ import (
 	"log/slog"
 	"net/http"
 	"online-photo-editor/internal/config"
+	"online-photo-editor/internal/http-server/handlers/image/animate"
 	"online-photo-editor/internal/http-server/handlers/image/blur"
 	"online-photo-editor/internal/http-server/handlers/image/brightness"
+	"online-photo-editor/internal/http-server/handlers/image/colorbalance"
+	"online-photo-editor/internal/http-server/handlers/image/colorize"
 	"online-photo-editor/internal/http-server/handlers/image/contrast"
 	"online-photo-editor/internal/http-server/handlers/image/convert"
 	"online-photo-editor/internal/http-server/handlers/image/crop"
+	"online-photo-editor/internal/http-server/handlers/image/exposure"
 	"online-photo-editor/internal/http-server/handlers/image/gamma"
+	"online-photo-editor/internal/http-server/handlers/image/hue"
+	"online-photo-editor/internal/http-server/handlers/image/lut"
 	"online-photo-editor/internal/http-server/handlers/image/processor"
 	"online-photo-editor/internal/http-server/handlers/image/resize"
+	"online-photo-editor/internal/http-server/handlers/image/responsive"
 	"online-photo-editor/internal/http-server/handlers/image/saturation"
+	"online-photo-editor/internal/http-server/handlers/image/selection"
 	"online-photo-editor/internal/http-server/handlers/image/sharpen"
+	"online-photo-editor/internal/http-server/handlers/image/smartcrop"
+	"online-photo-editor/internal/http-server/handlers/image/temperature"
+	"online-photo-editor/internal/http-server/handlers/image/tint"
+	"online-photo-editor/internal/http-server/handlers/image/trim"
 	"online-photo-editor/internal/http-server/handlers/image/upload"
+	"online-photo-editor/internal/http-server/handlers/image/vibrance"
+	"online-photo-editor/internal/http-server/handlers/image/whitebalance"
 	mwLogger "online-photo-editor/internal/http-server/middleware/logger"
 	"online-photo-editor/internal/lib/logger/handlers/slogpretty"
 	"online-photo-editor/internal/lib/logger/sl"
@@ -121,6 +135,8 @@ func setupRouter(log *slog.Logger, imageStorage *imgStorage.ImageStorage, storag
 
 	router.Post("/image", upload.New(log, imageStorage))
 
+	router.Post("/lut", lut.New(log, imageStorage))
+
 	router.Post("/image/crop", crop.New(log, imageStorage))
 
 	router.Post("/image/resize", resize.New(log, imageStorage))
@@ -139,8 +155,34 @@ func setupRouter(log *slog.Logger, imageStorage *imgStorage.ImageStorage, storag
 
 	router.Post("/image/sharpen", sharpen.New(log, imageStorage))
 
+	router.Post("/image/hue", hue.New(log, imageStorage))
+
+	router.Post("/image/color-balance", colorbalance.New(log, imageStorage))
+
+	router.Post("/image/tint", tint.New(log, imageStorage))
+
+	router.Post("/image/colorize", colorize.New(log, imageStorage))
+
+	router.Post("/image/vibrance", vibrance.New(log, imageStorage))
+
+	router.Post("/image/temperature", temperature.New(log, imageStorage))
+
+	router.Post("/image/white-balance", whitebalance.New(log, imageStorage))
+
+	router.Post("/image/exposure", exposure.New(log, imageStorage))
+
+	router.Post("/image/smart-crop", smartcrop.New(log, imageStorage))
+
+	router.Post("/image/trim", trim.New(log, imageStorage))
+
+	router.Post("/image/select", selection.New(log, imageStorage))
+
 	router.Post("/image/process", processor.New(log, imageStorage))
 
+	router.Post("/image/animate", animate.New(log, imageStorage))
+
+	router.Post("/image/responsive", responsive.New(log, imageStorage))
+
 	fileServer := http.FileServer(http.Dir(storagePath))
 	router.Handle("/images/*", http.StripPrefix("/images", fileServer))
 
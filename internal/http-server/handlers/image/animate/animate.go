@@ -0,0 +1,136 @@
+package animate
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"log/slog"
+	"net/http"
+	"online-photo-editor/internal/http-server/handlers/image/processor"
+	"online-photo-editor/internal/lib/api/animate"
+	"online-photo-editor/internal/lib/api/response"
+	"online-photo-editor/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type Frame struct {
+	ImageName string `json:"image_name" validate:"required,max=100"`
+	Delay     int    `json:"delay" validate:"min=0,max=655350"`
+}
+
+type Request struct {
+	animate.AnimateParams
+	Frames  []Frame                 `json:"frames" validate:"required,min=1,max=500,dive"`
+	Actions []processor.ImageAction `json:"actions"`
+}
+
+type Response struct {
+	response.Response
+	ImageUrl string `json:"image_url"`
+}
+
+func New(log *slog.Logger, imgAnimator processor.ImageProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.img.animate.New"
+
+		log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("empty request"))
+
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to decode request"))
+
+			return
+		}
+
+		if !response.Validation(log, w, r, req, http.StatusBadRequest) {
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		frames := make([]image.Image, 0, len(req.Frames))
+		delays := make([]int, 0, len(req.Frames))
+		pixels := 0
+
+		for _, frame := range req.Frames {
+			inputImg, err := imgAnimator.LoadImage(frame.ImageName)
+			if err != nil {
+				log.Error("failed to load image", sl.Err(err), slog.String("image_name", frame.ImageName))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("failed to load image "+frame.ImageName))
+				return
+			}
+
+			inputImg, _, _, ok := processor.ApplyActions(log, w, r, imgAnimator, inputImg, ".gif", req.Actions)
+			if !ok {
+				return
+			}
+
+			// Stop before holding more frames than the budget allows.
+			pixels += inputImg.Bounds().Dx() * inputImg.Bounds().Dy()
+			if pixels > animate.MaxPixels {
+				log.Error("frames too large", slog.Int("pixels", pixels))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error(fmt.Sprintf("frames exceed %d pixels in total, resize them first", animate.MaxPixels)))
+				return
+			}
+
+			frames = append(frames, inputImg)
+			delays = append(delays, frame.Delay)
+		}
+
+		anim, err := req.AnimateParams.AnimateImages(frames, delays)
+		if err != nil {
+			log.Error("failed to animate images", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to animate images"))
+			return
+		}
+
+		imgName, err := imgAnimator.GenerateName("anim", ".gif")
+		if err != nil {
+			log.Error("failed to generate name", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to generate name"))
+			return
+		}
+
+		imgUrl, err := imgAnimator.SaveAnimation(anim, imgName)
+		if err != nil {
+			log.Error("failed to save animation", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to save animation"))
+			return
+		}
+
+		log.Info("animation saved", slog.String("image url", imgUrl))
+
+		responseOK(w, r, imgUrl)
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, imgUrl string) {
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, Response{
+		Response: response.OK(),
+		ImageUrl: imgUrl,
+	})
+}
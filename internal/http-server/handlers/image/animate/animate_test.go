@@ -0,0 +1,83 @@
+package animate_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"net/http"
+	"net/http/httptest"
+	"online-photo-editor/internal/http-server/handlers/image/animate"
+	"online-photo-editor/internal/http-server/handlers/image/processor/mocks"
+	"online-photo-editor/internal/lib/logger/handlers/slogdiscard"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// blank is an image with bounds but no pixel data.
+type blank struct {
+	image.Rectangle
+}
+
+func (b blank) ColorModel() color.Model { return color.NRGBAModel }
+func (b blank) Bounds() image.Rectangle { return b.Rectangle }
+func (b blank) At(x, y int) color.Color { return color.NRGBA{} }
+
+func TestHandler_Animate_Success(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := animate.New(logger, mockProcessor)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"delay":  80,
+		"frames": []map[string]interface{}{{"image_name": "a.png"}, {"image_name": "b.png", "delay": 200}},
+	})
+	assert.NoError(t, err)
+
+	mockProcessor.On("LoadImage", "a.png").Return(image.NewNRGBA(image.Rect(0, 0, 10, 10)), nil)
+	mockProcessor.On("LoadImage", "b.png").Return(image.NewNRGBA(image.Rect(0, 0, 10, 10)), nil)
+	mockProcessor.On("GenerateName", "anim", ".gif").Return("anim.gif", nil)
+	mockProcessor.On("SaveAnimation", mock.Anything, "anim.gif").Return("/images/anim.gif", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/animate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockProcessor.AssertExpectations(t)
+}
+
+func TestHandler_Animate_RejectsTooManyPixels(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := animate.New(logger, mockProcessor)
+
+	frames := make([]map[string]interface{}, 3)
+	for i := range frames {
+		frames[i] = map[string]interface{}{"image_name": "big.png"}
+	}
+	body, err := json.Marshal(map[string]interface{}{"frames": frames})
+	assert.NoError(t, err)
+
+	mockProcessor.On("LoadImage", "big.png").Return(blank{image.Rect(0, 0, 8000, 8000)}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/animate", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	// The request stops at the second frame, which crosses the budget.
+	mockProcessor.AssertNumberOfCalls(t, "LoadImage", 2)
+}
@@ -80,7 +80,7 @@ func New(log *slog.Logger, imgBlur processor.ImageProcessor) http.HandlerFunc {
 			return
 		}
 
-		imgName, err := imgBlur.GenerateName("proc", filepath.Ext(req.ImageName))
+		imgName, err := imgBlur.GenerateName("proc", processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName)))
 		if err != nil {
 			log.Error("failed to generate name", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
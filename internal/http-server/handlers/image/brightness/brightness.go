@@ -79,7 +79,7 @@ func New(log *slog.Logger, imgBrightness processor.ImageProcessor) http.HandlerF
 			return
 		}
 
-		imgName, err := imgBrightness.GenerateName("proc", filepath.Ext(req.ImageName))
+		imgName, err := imgBrightness.GenerateName("proc", processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName)))
 		if err != nil {
 			log.Error("failed to generate name", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
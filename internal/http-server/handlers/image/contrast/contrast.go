@@ -79,7 +79,7 @@ func New(log *slog.Logger, imgContrast processor.ImageProcessor) http.HandlerFun
 			return
 		}
 
-		imgName, err := imgContrast.GenerateName("proc", filepath.Ext(req.ImageName))
+		imgName, err := imgContrast.GenerateName("proc", processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName)))
 		if err != nil {
 			log.Error("failed to generate name", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -2,6 +2,7 @@ package convert
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"online-photo-editor/internal/lib/api/convert"
 	"online-photo-editor/internal/lib/api/response"
 	"online-photo-editor/internal/lib/logger/sl"
+	"path/filepath"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/render"
@@ -69,11 +71,15 @@ func New(log *slog.Logger, imgConverter processor.ImageProcessor) http.HandlerFu
 			return
 		}
 
-		fileExt, err := req.ConvertParams.ConvertImage()
+		if req.Format == "" {
+			req.Format = processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName))
+		}
+
+		inputImg, fileExt, err := req.ConvertParams.ConvertImage(inputImg)
 		if err != nil {
-			log.Error("failed to crop image", sl.Err(err))
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error("failed to crop image"))
+			log.Error("failed to convert image", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("failed to convert image: %v", err)))
 			return
 		}
 
@@ -2,6 +2,7 @@ package crop
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -74,12 +75,12 @@ func New(log *slog.Logger, imgCropper processor.ImageProcessor) http.HandlerFunc
 		inputImg, err = req.CropParams.CropImage(inputImg)
 		if err != nil {
 			log.Error("failed to crop image", sl.Err(err))
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error("failed to crop image"))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("failed to crop image: %v", err)))
 			return
 		}
 
-		imgName, err := imgCropper.GenerateName("proc", filepath.Ext(req.ImageName))
+		imgName, err := imgCropper.GenerateName("proc", processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName)))
 		if err != nil {
 			log.Error("failed to generate name", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -0,0 +1,81 @@
+package lut
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"online-photo-editor/internal/http-server/handlers/image/processor"
+	"online-photo-editor/internal/lib/api/response"
+	"online-photo-editor/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+)
+
+type Response struct {
+	response.Response
+	LUTUrl string `json:"lut_url"`
+}
+
+// 32 MB max size, enough for a 65-point .cube file
+const maxLUTSize = 32 << 20
+
+func New(log *slog.Logger, lutSaver processor.ImageProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.img.lut.New"
+
+		log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxLUTSize)
+
+		err := r.ParseMultipartForm(maxLUTSize)
+		if err != nil {
+			log.Error("failed to parse multipart/form-data", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to parse multipart/form-data"))
+			return
+		}
+
+		log.Info("multipart/form-data parsed")
+
+		files := r.MultipartForm.File["lut"]
+		if len(files) != 1 {
+			log.Error("invalid number of files uploaded", slog.Int("file_count", len(files)))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("exactly one file must be uploaded"))
+			return
+		}
+
+		file, handler, err := r.FormFile("lut")
+		if err != nil {
+			log.Error("no file uploaded", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("no file uploaded"))
+			return
+		}
+		defer file.Close()
+
+		lutUrl, err := lutSaver.UploadLUT(file, handler)
+		if err != nil {
+			log.Error("failed to save lut", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("failed to save lut: %v", err)))
+			return
+		}
+
+		log.Info("lut saved", slog.String("lut url", lutUrl))
+
+		responseOK(w, r, lutUrl)
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, lutUrl string) {
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, Response{
+		Response: response.OK(),
+		LUTUrl:   lutUrl,
+	})
+}
@@ -4,8 +4,11 @@ package mocks
 
 import (
 	image "image"
+	gif "image/gif"
 	multipart "mime/multipart"
 
+	cube "online-photo-editor/internal/lib/cube"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -118,6 +121,64 @@ func (_m *ImageProcessor) LoadImage(imgName string) (image.Image, error) {
 	return r0, r1
 }
 
+// LoadLUT provides a mock function with given fields: lutName
+func (_m *ImageProcessor) LoadLUT(lutName string) (*cube.LUT, error) {
+	ret := _m.Called(lutName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LoadLUT")
+	}
+
+	var r0 *cube.LUT
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*cube.LUT, error)); ok {
+		return rf(lutName)
+	}
+	if rf, ok := ret.Get(0).(func(string) *cube.LUT); ok {
+		r0 = rf(lutName)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*cube.LUT)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(lutName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SaveAnimation provides a mock function with given fields: anim, imgName
+func (_m *ImageProcessor) SaveAnimation(anim *gif.GIF, imgName string) (string, error) {
+	ret := _m.Called(anim, imgName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAnimation")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*gif.GIF, string) (string, error)); ok {
+		return rf(anim, imgName)
+	}
+	if rf, ok := ret.Get(0).(func(*gif.GIF, string) string); ok {
+		r0 = rf(anim, imgName)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(*gif.GIF, string) error); ok {
+		r1 = rf(anim, imgName)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveImage provides a mock function with given fields: inputImg, imgName
 func (_m *ImageProcessor) SaveImage(inputImg image.Image, imgName string) (string, error) {
 	ret := _m.Called(inputImg, imgName)
@@ -174,6 +235,34 @@ func (_m *ImageProcessor) UploadImage(file multipart.File, handler *multipart.Fi
 	return r0, r1
 }
 
+// UploadLUT provides a mock function with given fields: file, handler
+func (_m *ImageProcessor) UploadLUT(file multipart.File, handler *multipart.FileHeader) (string, error) {
+	ret := _m.Called(file, handler)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UploadLUT")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(multipart.File, *multipart.FileHeader) (string, error)); ok {
+		return rf(file, handler)
+	}
+	if rf, ok := ret.Get(0).(func(multipart.File, *multipart.FileHeader) string); ok {
+		r0 = rf(file, handler)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(multipart.File, *multipart.FileHeader) error); ok {
+		r1 = rf(file, handler)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewImageProcessor creates a new instance of ImageProcessor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewImageProcessor(t interface {
@@ -5,23 +5,68 @@ import (
 	"errors"
 	"fmt"
 	"image"
+	"image/gif"
 	"io"
 	"log/slog"
 	"mime/multipart"
 	"net/http"
+	"online-photo-editor/internal/lib/api/autocontrast"
+	"online-photo-editor/internal/lib/api/autolevels"
+	"online-photo-editor/internal/lib/api/bilateral"
 	"online-photo-editor/internal/lib/api/blur"
+	"online-photo-editor/internal/lib/api/border"
+	"online-photo-editor/internal/lib/api/boxblur"
 	"online-photo-editor/internal/lib/api/brightness"
+	"online-photo-editor/internal/lib/api/chromakey"
+	"online-photo-editor/internal/lib/api/clahe"
+	"online-photo-editor/internal/lib/api/colorbalance"
+	"online-photo-editor/internal/lib/api/colorize"
 	"online-photo-editor/internal/lib/api/contrast"
 	"online-photo-editor/internal/lib/api/convert"
+	"online-photo-editor/internal/lib/api/convolve"
 	"online-photo-editor/internal/lib/api/crop"
+	"online-photo-editor/internal/lib/api/curves"
+	"online-photo-editor/internal/lib/api/denoise"
+	"online-photo-editor/internal/lib/api/drawing"
+	"online-photo-editor/internal/lib/api/edgedetect"
+	"online-photo-editor/internal/lib/api/emboss"
+	"online-photo-editor/internal/lib/api/equalize"
+	"online-photo-editor/internal/lib/api/exposure"
 	"online-photo-editor/internal/lib/api/gamma"
+	"online-photo-editor/internal/lib/api/gradientmap"
+	"online-photo-editor/internal/lib/api/grayscale"
+	"online-photo-editor/internal/lib/api/hue"
+	"online-photo-editor/internal/lib/api/invert"
+	"online-photo-editor/internal/lib/api/levels"
+	"online-photo-editor/internal/lib/api/liquidresize"
+	"online-photo-editor/internal/lib/api/lut"
+	"online-photo-editor/internal/lib/api/median"
+	"online-photo-editor/internal/lib/api/morphology"
+	"online-photo-editor/internal/lib/api/outline"
+	"online-photo-editor/internal/lib/api/pad"
+	"online-photo-editor/internal/lib/api/pixelate"
+	"online-photo-editor/internal/lib/api/posterize"
+	"online-photo-editor/internal/lib/api/redact"
 	"online-photo-editor/internal/lib/api/resize"
 	"online-photo-editor/internal/lib/api/response"
 	"online-photo-editor/internal/lib/api/saturation"
+	"online-photo-editor/internal/lib/api/sepia"
 	"online-photo-editor/internal/lib/api/sharpen"
+	"online-photo-editor/internal/lib/api/smartcrop"
+	"online-photo-editor/internal/lib/api/temperature"
+	"online-photo-editor/internal/lib/api/threshold"
+	"online-photo-editor/internal/lib/api/tint"
+	"online-photo-editor/internal/lib/api/trim"
+	"online-photo-editor/internal/lib/api/unsharpmask"
+	"online-photo-editor/internal/lib/api/vibrance"
+	"online-photo-editor/internal/lib/api/whitebalance"
+	"online-photo-editor/internal/lib/cube"
+	"online-photo-editor/internal/lib/format"
 	"online-photo-editor/internal/lib/logger/sl"
+	"online-photo-editor/internal/lib/mask"
 
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/go-chi/chi/middleware"
@@ -29,20 +74,69 @@ import (
 )
 
 const (
-	cropAction       = "crop"
-	resizeAction     = "resize"
-	convertAction    = "convert"
-	blurAction       = "blur"
-	gammaAction      = "gamma"
-	contrastAction   = "contrast"
-	sharpenAction    = "sharpen"
-	brightnessAction = "brightness"
-	saturationAction = "saturation"
+	cropAction         = "crop"
+	resizeAction       = "resize"
+	convertAction      = "convert"
+	blurAction         = "blur"
+	gammaAction        = "gamma"
+	contrastAction     = "contrast"
+	sharpenAction      = "sharpen"
+	brightnessAction   = "brightness"
+	saturationAction   = "saturation"
+	smartCropAction    = "smart_crop"
+	liquidResizeAction = "liquid_resize"
+	trimAction         = "trim"
+	padAction          = "pad"
+	extendCanvasAction = "extend_canvas"
+	borderAction       = "border"
+	hueAction          = "hue"
+	colorBalanceAction = "color_balance"
+	tintAction         = "tint"
+	colorizeAction     = "colorize"
+	vibranceAction     = "vibrance"
+	temperatureAction  = "temperature"
+	whiteBalanceAction = "white_balance"
+	exposureAction     = "exposure"
+	levelsAction       = "levels"
+	curvesAction       = "curves"
+	autoLevelsAction   = "auto_levels"
+	autoContrastAction = "auto_contrast"
+	equalizeAction     = "equalize"
+	claheAction        = "clahe"
+	lutAction          = "lut"
+	grayscaleAction    = "grayscale"
+	sepiaAction        = "sepia"
+	invertAction       = "invert"
+	duotoneAction      = "duotone"
+	gradientMapAction  = "gradient_map"
+	posterizeAction    = "posterize"
+	thresholdAction    = "threshold"
+	binarizeAction     = "binarize"
+	convolveAction     = "convolve"
+	edgeDetectAction   = "edge_detect"
+	embossAction       = "emboss"
+	outlineAction      = "outline"
+	boxBlurAction      = "box_blur"
+	unsharpMaskAction  = "unsharp_mask"
+	medianAction       = "median"
+	bilateralAction    = "bilateral"
+	denoiseAction      = "denoise"
+	dilateAction       = "dilate"
+	erodeAction        = "erode"
+	openAction         = "open"
+	closeAction        = "close"
+	gradientAction     = "gradient"
+	tophatAction       = "tophat"
+	pixelateAction     = "pixelate"
+	redactAction       = "redact"
+	chromaKeyAction    = "chroma_key"
+	drawAction         = "draw"
 )
 
 type ImageAction struct {
-	Action string      `json:"action" validate:"required,max=10"`
+	Action string      `json:"action" validate:"required,max=20"`
 	Params interface{} `json:"params" validate:"required"`
+	Mask   *mask.Mask  `json:"mask"`
 }
 
 type Request struct {
@@ -50,9 +144,18 @@ type Request struct {
 	ImageName string        `json:"image_name" validate:"required,max=100"`
 }
 
+// ActionResult carries what an action reports back besides the image, such
+// as the rectangle chosen by smart_crop.
+type ActionResult struct {
+	Index  int                 `json:"index"`
+	Action string              `json:"action"`
+	Rect   *response.Rectangle `json:"rect,omitempty"`
+}
+
 type Response struct {
 	response.Response
-	ImageUrl string `json:"image_url"`
+	ImageUrl string         `json:"image_url"`
+	Results  []ActionResult `json:"results,omitempty"`
 }
 
 //go:generate go run github.com/vektra/mockery/v2@v2.28.2 --name=ImageProcessor
@@ -60,9 +163,12 @@ type ImageProcessor interface {
 	FindImage(imgName string) (string, error)
 	LoadImage(imgName string) (image.Image, error)
 	SaveImage(inputImg image.Image, imgName string) (string, error)
+	SaveAnimation(anim *gif.GIF, imgName string) (string, error)
 	UploadImage(file multipart.File, handler *multipart.FileHeader) (string, error)
 	DeleteImage(imgName string) error
 	GenerateName(prefix string, fileExt string) (string, error)
+	UploadLUT(file multipart.File, handler *multipart.FileHeader) (string, error)
+	LoadLUT(lutName string) (*cube.LUT, error)
 }
 
 func New(log *slog.Logger, imgProcessor ImageProcessor) http.HandlerFunc {
@@ -99,6 +205,10 @@ func New(log *slog.Logger, imgProcessor ImageProcessor) http.HandlerFunc {
 
 		log.Info("request body decoded", slog.Any("request", req))
 
+		if !validateConvertActions(log, w, r, req.Actions) {
+			return
+		}
+
 		imgPath, err := imgProcessor.FindImage(req.ImageName)
 		if err != nil {
 			log.Error("failed to find image", sl.Err(err))
@@ -117,134 +227,13 @@ func New(log *slog.Logger, imgProcessor ImageProcessor) http.HandlerFunc {
 			return
 		}
 
-		for _, action := range req.Actions {
-			if !response.Validation(log, w, r, action, http.StatusBadRequest) {
-				return
-			}
-			switch action.Action {
-			case cropAction:
-				var params crop.CropParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid crop params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid crop params"))
-					return
-				}
-
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-
-				inputImg, err = params.CropImage(inputImg)
-			case resizeAction:
-				var params resize.ResizeParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid resize params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid resize params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				inputImg, err = params.ResizeImage(inputImg)
-			case blurAction:
-				var params blur.BlurParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid blur params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid blur params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				inputImg, err = params.BlurImage(inputImg)
-			case gammaAction:
-				var params gamma.GammaParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid gamma params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid gamma params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				inputImg, err = params.GammaImage(inputImg)
-			case contrastAction:
-				var params contrast.ContrastParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid gamma params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid gamma params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				inputImg, err = params.ContrastImage(inputImg)
-			case sharpenAction:
-				var params sharpen.SharpenParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid sharpen params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid sharpen params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				inputImg, err = params.SharpenImage(inputImg)
-			case brightnessAction:
-				var params brightness.BrightnessParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid sharpen params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid sharpen params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				inputImg, err = params.BrightnessImage(inputImg)
-			case saturationAction:
-				var params saturation.SaturationParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid sharpen params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid sharpen params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				inputImg, err = params.SaturationImage(inputImg)
-			case convertAction:
-				var params convert.ConvertParams
-				if err := decodeParams(action.Params, &params); err != nil {
-					log.Error("invalid convert params", sl.Err(err))
-					render.Status(r, http.StatusBadRequest)
-					render.JSON(w, r, response.Error("invalid convert params"))
-					return
-				}
-				if !response.Validation(log, w, r, params, http.StatusBadRequest) {
-					return
-				}
-				fileExt, err = params.ConvertImage()
-			default:
-				err = fmt.Errorf("field %s must be one of the allowed values`", action.Action)
-				log.Error("invalid action", sl.Err(err))
-				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, response.Error(err.Error()))
-				return
-			}
-			if err != nil {
-				log.Error("failed to perform action", sl.Err(err))
-				render.Status(r, http.StatusBadRequest)
-				render.JSON(w, r, response.Error(fmt.Sprintf("failed to perform action %s: %v", action.Action, err)))
-				return
-			}
+		inputImg, fileExt, results, ok := ApplyActions(log, w, r, imgProcessor, inputImg, fileExt, req.Actions)
+		if !ok {
+			return
+		}
+
+		if !hasAction(req.Actions, convertAction) {
+			fileExt = OutputFormat(w, r, inputImg, fileExt)
 		}
 
 		imgName, err := imgProcessor.GenerateName("proc", fileExt)
@@ -265,10 +254,736 @@ func New(log *slog.Logger, imgProcessor ImageProcessor) http.HandlerFunc {
 
 		log.Info("image saved", slog.String("image url", imgUrl))
 
-		responseOK(w, r, imgUrl)
+		responseOK(w, r, imgUrl, results)
 	}
 }
 
+// ApplyActions runs the actions over img in order and returns the result along
+// with the file extension it should be saved with and anything the actions
+// reported. Resources referenced by actions, such as LUTs, are loaded through
+// imgProcessor. On failure the error response is written and ok is false.
+func ApplyActions(log *slog.Logger, w http.ResponseWriter, r *http.Request, imgProcessor ImageProcessor, img image.Image, fileExt string, actions []ImageAction) (image.Image, string, []ActionResult, bool) {
+	var err error
+	var results []ActionResult
+
+	for i, action := range actions {
+		if !response.Validation(log, w, r, action, http.StatusBadRequest) {
+			return nil, "", nil, false
+		}
+		input := img
+		switch action.Action {
+		case cropAction:
+			var params crop.CropParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid crop params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid crop params"))
+				return nil, "", nil, false
+			}
+
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+
+			img, err = params.CropImage(img)
+		case resizeAction:
+			var params resize.ResizeParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid resize params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid resize params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ResizeImage(img)
+		case blurAction:
+			var params blur.BlurParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid blur params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid blur params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.BlurImage(img)
+		case gammaAction:
+			var params gamma.GammaParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid gamma params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid gamma params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.GammaImage(img)
+		case contrastAction:
+			var params contrast.ContrastParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid gamma params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid gamma params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ContrastImage(img)
+		case sharpenAction:
+			var params sharpen.SharpenParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid sharpen params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid sharpen params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.SharpenImage(img)
+		case brightnessAction:
+			var params brightness.BrightnessParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid sharpen params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid sharpen params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.BrightnessImage(img)
+		case saturationAction:
+			var params saturation.SaturationParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid sharpen params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid sharpen params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.SaturationImage(img)
+		case smartCropAction:
+			var params smartcrop.SmartCropParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid smart crop params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid smart crop params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			var rect image.Rectangle
+			img, rect, err = params.SmartCropImage(img)
+			results = append(results, ActionResult{Index: i, Action: action.Action, Rect: response.Rect(rect)})
+		case liquidResizeAction:
+			var params liquidresize.LiquidResizeParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid liquid resize params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid liquid resize params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.LiquidResizeImage(img)
+		case trimAction:
+			var params trim.TrimParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid trim params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid trim params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			var rect image.Rectangle
+			img, rect, err = params.TrimImage(img)
+			results = append(results, ActionResult{Index: i, Action: action.Action, Rect: response.Rect(rect)})
+		case padAction, extendCanvasAction:
+			var params pad.PadParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid pad params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid pad params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.PadImage(img)
+		case borderAction:
+			var params border.BorderParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid border params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid border params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.BorderImage(img)
+		case hueAction:
+			var params hue.HueParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid hue params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid hue params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.HueImage(img)
+		case colorBalanceAction:
+			var params colorbalance.ColorBalanceParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid color_balance params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid color_balance params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ColorBalanceImage(img)
+		case tintAction:
+			var params tint.TintParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid tint params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid tint params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.TintImage(img)
+		case colorizeAction:
+			var params colorize.ColorizeParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid colorize params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid colorize params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ColorizeImage(img)
+		case vibranceAction:
+			var params vibrance.VibranceParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid vibrance params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid vibrance params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.VibranceImage(img)
+		case temperatureAction:
+			var params temperature.TemperatureParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid temperature params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid temperature params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.TemperatureImage(img)
+		case whiteBalanceAction:
+			var params whitebalance.WhiteBalanceParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid white_balance params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid white_balance params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.WhiteBalanceImage(img)
+		case exposureAction:
+			var params exposure.ExposureParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid exposure params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid exposure params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ExposureImage(img)
+		case levelsAction:
+			var params levels.LevelsParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid levels params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid levels params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.LevelsImage(img)
+		case curvesAction:
+			var params curves.CurvesParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid curves params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid curves params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.CurvesImage(img)
+		case autoLevelsAction:
+			var params autolevels.AutoLevelsParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid auto_levels params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid auto_levels params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.AutoLevelsImage(img)
+		case autoContrastAction:
+			var params autocontrast.AutoContrastParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid auto_contrast params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid auto_contrast params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.AutoContrastImage(img)
+		case equalizeAction:
+			var params equalize.EqualizeParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid equalize params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid equalize params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.EqualizeImage(img)
+		case claheAction:
+			var params clahe.ClaheParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid clahe params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid clahe params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ClaheImage(img)
+		case lutAction:
+			var params lut.LUTParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid lut params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid lut params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			var table *cube.LUT
+			if table, err = imgProcessor.LoadLUT(params.LUTName); err != nil {
+				log.Error("failed to load lut", sl.Err(err))
+				render.Status(r, http.StatusNotFound)
+				render.JSON(w, r, response.Error("failed to load lut"))
+				return nil, "", nil, false
+			}
+			img, err = params.LUTImage(img, table)
+		case grayscaleAction:
+			var params grayscale.GrayscaleParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid grayscale params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid grayscale params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.GrayscaleImage(img)
+		case sepiaAction:
+			var params sepia.SepiaParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid sepia params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid sepia params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.SepiaImage(img)
+		case invertAction:
+			var params invert.InvertParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid invert params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid invert params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.InvertImage(img)
+		case duotoneAction, gradientMapAction:
+			var params gradientmap.GradientMapParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid gradient map params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid gradient map params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.GradientMapImage(img)
+		case posterizeAction:
+			var params posterize.PosterizeParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid posterize params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid posterize params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.PosterizeImage(img)
+		case thresholdAction, binarizeAction:
+			var params threshold.ThresholdParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid threshold params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid threshold params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ThresholdImage(img)
+		case convolveAction:
+			var params convolve.ConvolveParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid convolve params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid convolve params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.ConvolveImage(img)
+		case edgeDetectAction:
+			var params edgedetect.EdgeDetectParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid edge detect params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid edge detect params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.EdgeDetectImage(img)
+		case embossAction:
+			var params emboss.EmbossParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid emboss params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid emboss params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.EmbossImage(img)
+		case outlineAction:
+			var params outline.OutlineParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid outline params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid outline params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.OutlineImage(img)
+		case boxBlurAction:
+			var params boxblur.BoxBlurParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid box blur params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid box blur params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.BoxBlurImage(img)
+		case unsharpMaskAction:
+			var params unsharpmask.UnsharpMaskParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid unsharp mask params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid unsharp mask params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.UnsharpMaskImage(img)
+		case medianAction:
+			var params median.MedianParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid median params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid median params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.MedianImage(img)
+		case bilateralAction:
+			var params bilateral.BilateralParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid bilateral params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid bilateral params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.BilateralImage(img)
+		case denoiseAction:
+			var params denoise.DenoiseParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid denoise params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid denoise params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.DenoiseImage(img)
+		case dilateAction, erodeAction, openAction, closeAction, gradientAction, tophatAction:
+			var params morphology.MorphologyParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid morphology params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid morphology params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.MorphologyImage(img, action.Action)
+		case pixelateAction:
+			var params pixelate.PixelateParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid pixelate params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid pixelate params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.PixelateImage(img)
+		case redactAction:
+			var params redact.RedactParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid redact params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid redact params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.RedactImage(img)
+		case chromaKeyAction:
+			var params chromakey.ChromaKeyParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid chroma_key params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid chroma_key params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			if img, err = params.ChromaKeyImage(img); err == nil {
+				// Switch to a format that keeps the soft edges.
+				fileExt = OutputFormat(w, r, img, fileExt)
+			}
+		case drawAction:
+			var params drawing.DrawParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid draw params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid draw params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			img, err = params.DrawImage(img)
+		case convertAction:
+			var params convert.ConvertParams
+			if err := decodeParams(action.Params, &params); err != nil {
+				log.Error("invalid convert params", sl.Err(err))
+				render.Status(r, http.StatusBadRequest)
+				render.JSON(w, r, response.Error("invalid convert params"))
+				return nil, "", nil, false
+			}
+			if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+				return nil, "", nil, false
+			}
+			if params.Format == "" {
+				params.Format = OutputFormat(w, r, img, fileExt)
+			}
+			img, fileExt, err = params.ConvertImage(img)
+		default:
+			err = fmt.Errorf("field %s must be one of the allowed values`", action.Action)
+			log.Error("invalid action", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(err.Error()))
+			return nil, "", nil, false
+		}
+		if err != nil {
+			log.Error("failed to perform action", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("failed to perform action %s: %v", action.Action, err)))
+			return nil, "", nil, false
+		}
+		if action.Mask != nil {
+			var ok bool
+			if img, ok = applyMask(log, w, r, imgProcessor, input, img, action); !ok {
+				return nil, "", nil, false
+			}
+		}
+	}
+
+	return img, fileExt, results, true
+}
+
+// applyMask blends the result of an action with its input through the
+// action's mask, so only the selected part of the image changes.
+func applyMask(log *slog.Logger, w http.ResponseWriter, r *http.Request, imgProcessor ImageProcessor, input, output image.Image, action ImageAction) (image.Image, bool) {
+	if !response.Validation(log, w, r, action.Mask, http.StatusBadRequest) {
+		return nil, false
+	}
+
+	if input.Bounds().Dx() != output.Bounds().Dx() || input.Bounds().Dy() != output.Bounds().Dy() {
+		err := fmt.Errorf("action %s changes the image size and cannot be masked", action.Action)
+		log.Error("invalid mask", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(err.Error()))
+		return nil, false
+	}
+
+	var stored image.Image
+	if action.Mask.MaskName != "" {
+		var err error
+		if stored, err = imgProcessor.LoadImage(action.Mask.MaskName); err != nil {
+			log.Error("failed to load mask", sl.Err(err))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("failed to load mask"))
+			return nil, false
+		}
+	}
+
+	alpha, err := action.Mask.Build(input, stored)
+	if err != nil {
+		log.Error("invalid mask", sl.Err(err))
+		render.Status(r, http.StatusBadRequest)
+		render.JSON(w, r, response.Error(fmt.Sprintf("invalid mask for action %s: %v", action.Action, err)))
+		return nil, false
+	}
+
+	return mask.Blend(input, output, alpha), true
+}
+
+// OutputFormat picks the extension to save img with from the request's Accept
+// header, falling back to the source format, and marks the response as
+// varying on Accept.
+func OutputFormat(w http.ResponseWriter, r *http.Request, img image.Image, sourceExt string) string {
+	if !slices.Contains(w.Header().Values("Vary"), "Accept") {
+		w.Header().Add("Vary", "Accept")
+	}
+
+	return format.Negotiate(r.Header.Get("Accept"), sourceExt, convert.Transparency(img)).Extension
+}
+
+func hasAction(actions []ImageAction, name string) bool {
+	for _, action := range actions {
+		if action.Action == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateConvertActions checks the target formats of all convert actions
+// before any processing work is done.
+func validateConvertActions(log *slog.Logger, w http.ResponseWriter, r *http.Request, actions []ImageAction) bool {
+	for _, action := range actions {
+		if action.Action != convertAction {
+			continue
+		}
+
+		var params convert.ConvertParams
+		if err := decodeParams(action.Params, &params); err != nil {
+			log.Error("invalid convert params", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("invalid convert params"))
+			return false
+		}
+		if !response.Validation(log, w, r, params, http.StatusBadRequest) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func decodeParams(input interface{}, output interface{}) error {
 	data, err := json.Marshal(input)
 	if err != nil {
@@ -277,10 +992,11 @@ func decodeParams(input interface{}, output interface{}) error {
 	return json.Unmarshal(data, output)
 }
 
-func responseOK(w http.ResponseWriter, r *http.Request, imgUrl string) {
+func responseOK(w http.ResponseWriter, r *http.Request, imgUrl string, results []ActionResult) {
 	render.Status(r, http.StatusOK)
 	render.JSON(w, r, Response{
 		Response: response.OK(),
 		ImageUrl: imgUrl,
+		Results:  results,
 	})
 }
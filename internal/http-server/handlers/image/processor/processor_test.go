@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"image"
+	"image/color"
+	"image/draw"
 	"net/http"
 	"net/http/httptest"
 	"online-photo-editor/internal/http-server/handlers/image/processor"
 	"online-photo-editor/internal/http-server/handlers/image/processor/mocks"
+	"online-photo-editor/internal/lib/api/response"
+	"online-photo-editor/internal/lib/geometry"
 	"online-photo-editor/internal/lib/logger/handlers/slogdiscard"
+	"online-photo-editor/internal/lib/mask"
 	"testing"
 
 	"github.com/go-chi/render"
@@ -87,3 +92,633 @@ func TestHandler_ProcessImage_ImageNotFound(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "failed to find image", response["error"])
 }
+
+func TestHandler_ProcessImage_ConvertAlphaWithoutBackground(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "convert", Params: map[string]interface{}{"format": "jpg"}},
+		},
+		ImageName: "cutout.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	mockProcessor.On("FindImage", "cutout.png").Return("/path/to/cutout.png", nil)
+	mockProcessor.On("LoadImage", "cutout.png").Return(image.NewNRGBA(image.Rect(0, 0, 10, 10)), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockProcessor.AssertNotCalled(t, "SaveImage", mock.Anything, mock.Anything)
+}
+
+func TestHandler_ProcessImage_ConvertAlphaWithBackground(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "convert", Params: map[string]interface{}{"format": "jpg", "background": "#ffffff"}},
+		},
+		ImageName: "cutout.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	opaque := mock.MatchedBy(func(img image.Image) bool {
+		_, _, _, a := img.At(0, 0).RGBA()
+		return a == 0xffff
+	})
+
+	mockProcessor.On("FindImage", "cutout.png").Return("/path/to/cutout.png", nil)
+	mockProcessor.On("LoadImage", "cutout.png").Return(image.NewNRGBA(image.Rect(0, 0, 10, 10)), nil)
+	mockProcessor.On("GenerateName", "proc", ".jpg").Return("new-image.jpg", nil)
+	mockProcessor.On("SaveImage", opaque, "new-image.jpg").Return("/path/to/new-image.jpg", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockProcessor.AssertExpectations(t)
+}
+
+func TestHandler_ProcessImage_UnsupportedFormat(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "resize", Params: map[string]interface{}{"width": 100, "height": 100}},
+			{Action: "convert", Params: map[string]interface{}{"format": "xcf"}},
+		},
+		ImageName: "test-image.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	var response map[string]string
+	err = render.DecodeJSON(resp.Body, &response)
+	assert.NoError(t, err)
+	assert.Contains(t, response["error"], "jpeg, png, gif, bmp, tiff")
+	mockProcessor.AssertNotCalled(t, "LoadImage", mock.Anything)
+}
+
+func TestHandler_ProcessImage_NegotiatesFormat(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "brightness", Params: map[string]interface{}{"percentage": 10}},
+		},
+		ImageName: "photo.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	opaque := image.NewGray(image.Rect(0, 0, 10, 10))
+
+	mockProcessor.On("FindImage", "photo.png").Return("/path/to/photo.png", nil)
+	mockProcessor.On("LoadImage", "photo.png").Return(opaque, nil)
+	mockProcessor.On("GenerateName", "proc", ".jpg").Return("new-image.jpg", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.jpg").Return("/path/to/new-image.jpg", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, image/jpeg;q=0.9, image/*;q=0.5")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Accept", resp.Header.Get("Vary"))
+	mockProcessor.AssertExpectations(t)
+}
+
+func TestHandler_ProcessImage_NegotiatesWebP(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "brightness", Params: map[string]interface{}{"percentage": 10}},
+		},
+		ImageName: "photo.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	mockProcessor.On("FindImage", "photo.png").Return("/path/to/photo.png", nil)
+	mockProcessor.On("LoadImage", "photo.png").Return(image.NewRGBA(image.Rect(0, 0, 10, 10)), nil)
+	mockProcessor.On("GenerateName", "proc", ".webp").Return("new-image.webp", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.webp").Return("/path/to/new-image.webp", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "image/avif,image/webp,*/*")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Accept", resp.Header.Get("Vary"))
+	mockProcessor.AssertExpectations(t)
+}
+
+func TestHandler_ProcessImage_TrimReportsRect(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "trim", Params: map[string]interface{}{"tolerance": 5}},
+		},
+		ImageName: "scan.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	scan := image.NewNRGBA(image.Rect(0, 0, 100, 80))
+	draw.Draw(scan, scan.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(scan, image.Rect(10, 20, 60, 70), image.NewUniform(color.Black), image.Point{}, draw.Src)
+
+	mockProcessor.On("FindImage", "scan.png").Return("/path/to/scan.png", nil)
+	mockProcessor.On("LoadImage", "scan.png").Return(scan, nil)
+	mockProcessor.On("GenerateName", "proc", ".png").Return("new-image.png", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.png").Return("/path/to/new-image.png", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result processor.Response
+	err = render.DecodeJSON(resp.Body, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, []processor.ActionResult{
+		{Index: 0, Action: "trim", Rect: &response.Rectangle{X: 10, Y: 20, Width: 50, Height: 50}},
+	}, result.Results)
+}
+
+func TestHandler_ProcessImage_MaskLimitsAction(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{
+				Action: "invert",
+				Params: map[string]interface{}{},
+				Mask:   &mask.Mask{Region: mask.Region{Rect: &geometry.Rect{X: 0, Y: 0, Width: 50, Height: 80}}},
+			},
+		},
+		ImageName: "photo.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	photo := image.NewNRGBA(image.Rect(0, 0, 100, 80))
+	draw.Draw(photo, photo.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+
+	var saved image.Image
+	mockProcessor.On("FindImage", "photo.png").Return("/path/to/photo.png", nil)
+	mockProcessor.On("LoadImage", "photo.png").Return(photo, nil)
+	mockProcessor.On("GenerateName", "proc", ".png").Return("new-image.png", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.png").Run(func(args mock.Arguments) {
+		saved = args.Get(0).(image.Image)
+	}).Return("/path/to/new-image.png", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if assert.NotNil(t, saved) {
+		assert.Equal(t, color.NRGBAModel.Convert(color.Black), color.NRGBAModel.Convert(saved.At(10, 40)))
+		assert.Equal(t, color.NRGBAModel.Convert(color.White), color.NRGBAModel.Convert(saved.At(90, 40)))
+	}
+}
+
+func TestHandler_ProcessImage_MaskRejectsResize(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{
+				Action: "resize",
+				Params: map[string]interface{}{"width": 50, "height": 50},
+				Mask:   &mask.Mask{Region: mask.Region{Ellipse: &mask.Ellipse{X: 50, Y: 50, RadiusX: 20, RadiusY: 20}}},
+			},
+		},
+		ImageName: "photo.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	mockProcessor.On("FindImage", "photo.png").Return("/path/to/photo.png", nil)
+	mockProcessor.On("LoadImage", "photo.png").Return(image.NewRGBA(image.Rect(0, 0, 100, 100)), nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+func whiteCanvas(w, h int) *image.NRGBA {
+	canvas := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	return canvas
+}
+
+func nrgbaAt(img image.Image, x, y int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+}
+
+func TestHandler_ProcessImage_MaskCoversHugeRegion(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{
+				Action: "invert",
+				Params: map[string]interface{}{},
+				Mask:   &mask.Mask{Region: mask.Region{Ellipse: &mask.Ellipse{X: 10, Y: 10, RadiusX: 1e6, RadiusY: 1e6}}},
+			},
+		},
+		ImageName: "photo.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	var saved image.Image
+	mockProcessor.On("FindImage", "photo.png").Return("/path/to/photo.png", nil)
+	mockProcessor.On("LoadImage", "photo.png").Return(whiteCanvas(20, 20), nil)
+	mockProcessor.On("GenerateName", "proc", ".png").Return("new-image.png", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.png").Run(func(args mock.Arguments) {
+		saved = args.Get(0).(image.Image)
+	}).Return("/path/to/new-image.png", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	if assert.NotNil(t, saved) {
+		assert.Equal(t, color.NRGBA{A: 255}, nrgbaAt(saved, 0, 0))
+		assert.Equal(t, color.NRGBA{A: 255}, nrgbaAt(saved, 19, 19))
+	}
+}
+
+func TestHandler_ProcessImage_MaskRejectsFarCoordinates(t *testing.T) {
+	regions := map[string]mask.Region{
+		"rect":    {Rect: &geometry.Rect{X: 0, Y: 0, Width: 1e8, Height: 1e8}},
+		"ellipse": {Ellipse: &mask.Ellipse{X: 0, Y: 0, RadiusX: 1e8, RadiusY: 1e8}},
+		"polygon": {Polygon: []geometry.Point{{X: -1e8, Y: -1e8}, {X: 1e8, Y: -1e8}, {X: 0, Y: 1e8}}},
+	}
+
+	for name, region := range regions {
+		t.Run(name, func(t *testing.T) {
+			mockProcessor := new(mocks.ImageProcessor)
+			logger := slogdiscard.NewDiscardLogger()
+			handler := processor.New(logger, mockProcessor)
+
+			reqBody := processor.Request{
+				Actions: []processor.ImageAction{
+					{Action: "invert", Params: map[string]interface{}{}, Mask: &mask.Mask{Region: region}},
+				},
+				ImageName: "photo.png",
+			}
+
+			body, err := json.Marshal(reqBody)
+			assert.NoError(t, err)
+
+			mockProcessor.On("FindImage", "photo.png").Return("/path/to/photo.png", nil)
+			mockProcessor.On("LoadImage", "photo.png").Return(whiteCanvas(20, 20), nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+}
+
+func TestHandler_ProcessImage_ChromaKeyKeepsAlpha(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "chroma_key", Params: map[string]interface{}{"color": "#ffffff", "tolerance": 5}},
+		},
+		ImageName: "product.jpg",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	product := image.NewNRGBA(image.Rect(0, 0, 100, 80))
+	draw.Draw(product, product.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(product, image.Rect(30, 20, 70, 60), image.NewUniform(color.NRGBA{R: 200, G: 40, B: 40, A: 255}), image.Point{}, draw.Src)
+
+	var saved image.Image
+	mockProcessor.On("FindImage", "product.jpg").Return("/path/to/product.jpg", nil)
+	mockProcessor.On("LoadImage", "product.jpg").Return(product, nil)
+	mockProcessor.On("GenerateName", "proc", ".png").Return("new-image.png", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.png").Run(func(args mock.Arguments) {
+		saved = args.Get(0).(image.Image)
+	}).Return("/path/to/new-image.png", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockProcessor.AssertExpectations(t)
+	if assert.NotNil(t, saved) {
+		_, _, _, a := saved.At(5, 5).RGBA()
+		assert.Zero(t, a)
+		_, _, _, a = saved.At(50, 40).RGBA()
+		assert.Equal(t, uint32(0xffff), a)
+	}
+}
+func TestHandler_ProcessImage_ChromaKeySoftEdgesAvoidGIF(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "chroma_key", Params: map[string]interface{}{"color": "#ffffff", "tolerance": 5, "softness": 50}},
+		},
+		ImageName: "banner.gif",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	// A fade from white to red leaves semi-transparent pixels, which GIF
+	// cannot store.
+	banner := image.NewNRGBA(image.Rect(0, 0, 100, 10))
+	for x := 0; x < 100; x++ {
+		v := uint8(255 - x*2)
+		draw.Draw(banner, image.Rect(x, 0, x+1, 10), image.NewUniform(color.NRGBA{R: 255, G: v, B: v, A: 255}), image.Point{}, draw.Src)
+	}
+
+	mockProcessor.On("FindImage", "banner.gif").Return("/path/to/banner.gif", nil)
+	mockProcessor.On("LoadImage", "banner.gif").Return(banner, nil)
+	mockProcessor.On("GenerateName", "proc", ".png").Return("new-image.png", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.png").Return("/path/to/new-image.png", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "image/gif")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{"Accept"}, resp.Header.Values("Vary"))
+	mockProcessor.AssertExpectations(t)
+}
+
+// drawShapes runs a draw action over canvas and returns the status code and
+// the saved image.
+func drawShapes(t *testing.T, canvas image.Image, shapes []map[string]interface{}) (int, image.Image) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := processor.New(logger, mockProcessor)
+
+	reqBody := processor.Request{
+		Actions: []processor.ImageAction{
+			{Action: "draw", Params: map[string]interface{}{"shapes": shapes}},
+		},
+		ImageName: "canvas.png",
+	}
+
+	body, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+
+	var saved image.Image
+	mockProcessor.On("FindImage", "canvas.png").Return("/path/to/canvas.png", nil)
+	mockProcessor.On("LoadImage", "canvas.png").Return(canvas, nil)
+	mockProcessor.On("GenerateName", "proc", ".png").Return("new-image.png", nil)
+	mockProcessor.On("SaveImage", mock.Anything, "new-image.png").Run(func(args mock.Arguments) {
+		saved = args.Get(0).(image.Image)
+	}).Return("/path/to/new-image.png", nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/process", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	return resp.StatusCode, saved
+}
+
+func TestHandler_ProcessImage_DrawArrow(t *testing.T) {
+	status, saved := drawShapes(t, whiteCanvas(100, 100), []map[string]interface{}{
+		{
+			"type":         "arrow",
+			"points":       []map[string]float64{{"x": 10, "y": 50}, {"x": 90, "y": 50}},
+			"stroke":       "#ff0000",
+			"stroke_width": 4,
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, status)
+	if !assert.NotNil(t, saved) {
+		return
+	}
+
+	red := color.NRGBA{R: 255, A: 255}
+	white := color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	// The shaft is 4 pixels wide and the head 16 pixels long and wide.
+	assert.Equal(t, red, nrgbaAt(saved, 30, 50))
+	assert.Equal(t, white, nrgbaAt(saved, 30, 54))
+	assert.Equal(t, red, nrgbaAt(saved, 76, 55))
+	assert.Equal(t, red, nrgbaAt(saved, 86, 50))
+	assert.Equal(t, white, nrgbaAt(saved, 92, 50))
+}
+
+func TestHandler_ProcessImage_DrawRoundedRectClampsRadius(t *testing.T) {
+	status, saved := drawShapes(t, whiteCanvas(40, 40), []map[string]interface{}{
+		{
+			"type":   "rounded_rect",
+			"rect":   map[string]int{"x": 10, "y": 10, "width": 20, "height": 10},
+			"radius": 100,
+			"fill":   "#000000",
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, status)
+	if !assert.NotNil(t, saved) {
+		return
+	}
+
+	// The radius is clamped to half the height, so the ends are half disks
+	// and the middle of the top edge is still straight.
+	assert.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, nrgbaAt(saved, 10, 10))
+	assert.Equal(t, color.NRGBA{A: 255}, nrgbaAt(saved, 20, 10))
+	assert.Equal(t, color.NRGBA{A: 255}, nrgbaAt(saved, 11, 15))
+}
+
+func TestHandler_ProcessImage_DrawWithoutAntialias(t *testing.T) {
+	status, saved := drawShapes(t, whiteCanvas(60, 60), []map[string]interface{}{
+		{
+			"type":      "ellipse",
+			"ellipse":   map[string]float64{"x": 30, "y": 30, "radius_x": 20.3, "radius_y": 12.7},
+			"fill":      "#000000",
+			"antialias": false,
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, status)
+	if !assert.NotNil(t, saved) {
+		return
+	}
+
+	for y := 0; y < 60; y++ {
+		for x := 0; x < 60; x++ {
+			if c := nrgbaAt(saved, x, y); c.R != 0 && c.R != 255 {
+				t.Fatalf("pixel (%d, %d) is %v, want black or white", x, y, c)
+			}
+		}
+	}
+}
+
+func TestHandler_ProcessImage_DrawOpacityOverTransparent(t *testing.T) {
+	status, saved := drawShapes(t, image.NewNRGBA(image.Rect(0, 0, 20, 20)), []map[string]interface{}{
+		{
+			"type":    "rect",
+			"rect":    map[string]int{"x": 0, "y": 0, "width": 20, "height": 20},
+			"fill":    "#ff0000",
+			"opacity": 50,
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, status)
+	if assert.NotNil(t, saved) {
+		// The color is kept at full strength and only the alpha is reduced.
+		assert.Equal(t, color.NRGBA{R: 255, A: 128}, nrgbaAt(saved, 10, 10))
+	}
+}
+
+func TestHandler_ProcessImage_DrawRejectsFarCoordinates(t *testing.T) {
+	status, _ := drawShapes(t, whiteCanvas(20, 20), []map[string]interface{}{
+		{
+			"type":   "polygon",
+			"points": []map[string]float64{{"x": -1e39, "y": -1e39}, {"x": 1e39, "y": -1e39}, {"x": 0, "y": 1e39}},
+			"fill":   "#000000",
+		},
+	})
+
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+func TestHandler_ProcessImage_DrawClipsOffCanvasGeometry(t *testing.T) {
+	status, saved := drawShapes(t, whiteCanvas(20, 20), []map[string]interface{}{
+		{
+			"type":   "polygon",
+			"points": []map[string]float64{{"x": -1e6, "y": -1e6}, {"x": 1e6, "y": -1e6}, {"x": 0, "y": 1e6}},
+			"fill":   "#000000",
+		},
+	})
+
+	assert.Equal(t, http.StatusOK, status)
+	if assert.NotNil(t, saved) {
+		assert.Equal(t, color.NRGBA{A: 255}, nrgbaAt(saved, 10, 10))
+	}
+}
@@ -2,6 +2,7 @@ package resize
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -74,13 +75,13 @@ func New(log *slog.Logger, imgResize processor.ImageProcessor) http.HandlerFunc
 
 		inputImg, err = req.ResizeParams.ResizeImage(inputImg)
 		if err != nil {
-			log.Error("failed to crop image", sl.Err(err))
-			render.Status(r, http.StatusNotFound)
-			render.JSON(w, r, response.Error("failed to crop image"))
+			log.Error("failed to resize image", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("failed to resize image: %v", err)))
 			return
 		}
 
-		imgName, err := imgResize.GenerateName("proc", filepath.Ext(req.ImageName))
+		imgName, err := imgResize.GenerateName("proc", processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName)))
 		if err != nil {
 			log.Error("failed to generate name", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -0,0 +1,158 @@
+package responsive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"online-photo-editor/internal/http-server/handlers/image/processor"
+	"online-photo-editor/internal/lib/api/convert"
+	"online-photo-editor/internal/lib/api/response"
+	"online-photo-editor/internal/lib/api/responsive"
+	"online-photo-editor/internal/lib/format"
+	"online-photo-editor/internal/lib/logger/sl"
+	"path/filepath"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	responsive.ResponsiveParams
+	ImageName string `json:"image_name" validate:"required,max=100"`
+}
+
+type ImageSet struct {
+	Images map[int]string `json:"images"`
+	Srcset string         `json:"srcset"`
+}
+
+type Response struct {
+	response.Response
+	ImageSet
+	Formats map[string]ImageSet `json:"formats,omitempty"`
+}
+
+func New(log *slog.Logger, imgResizer processor.ImageProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.img.responsive.New"
+
+		log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("empty request"))
+
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to decode request"))
+
+			return
+		}
+
+		if !response.Validation(log, w, r, req, http.StatusBadRequest) {
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		inputImg, err := imgResizer.LoadImage(req.ImageName)
+		if err != nil {
+			log.Error("failed to load image", sl.Err(err))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("failed to load image"))
+			return
+		}
+
+		// Aliases such as jpg and jpeg name the same format, which is only
+		// encoded once.
+		var formats []string
+		seen := make(map[string]bool, len(req.Formats))
+		for _, f := range req.Formats {
+			imgFormat, _ := format.Lookup(f)
+			if !seen[imgFormat.Name] {
+				seen[imgFormat.Name] = true
+				formats = append(formats, f)
+			}
+		}
+		if len(formats) == 0 {
+			formats = []string{processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName))}
+		}
+
+		resized, err := req.ResponsiveParams.ResizeImages(inputImg)
+		if err != nil {
+			log.Error("failed to resize image", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to resize image"))
+			return
+		}
+
+		sets := make(map[string]ImageSet, len(formats))
+		for _, f := range formats {
+			imgFormat, _ := format.Lookup(f)
+			convertParams := convert.ConvertParams{Format: f, Background: req.Background}
+			urls := make(map[int]string, len(resized))
+
+			for width, img := range resized {
+				img, fileExt, err := convertParams.ConvertImage(img)
+				if err != nil {
+					log.Error("failed to convert image", sl.Err(err))
+					render.Status(r, http.StatusBadRequest)
+					render.JSON(w, r, response.Error(fmt.Sprintf("failed to convert image: %v", err)))
+					return
+				}
+
+				imgName, err := imgResizer.GenerateName(fmt.Sprintf("w%d", width), fileExt)
+				if err != nil {
+					log.Error("failed to generate name", sl.Err(err))
+					render.Status(r, http.StatusInternalServerError)
+					render.JSON(w, r, response.Error("failed to generate name"))
+					return
+				}
+
+				imgUrl, err := imgResizer.SaveImage(img, imgName)
+				if err != nil {
+					log.Error("failed to save image", sl.Err(err))
+					render.Status(r, http.StatusUnsupportedMediaType)
+					render.JSON(w, r, response.Error("failed to save image"))
+					return
+				}
+
+				urls[width] = imgUrl
+			}
+
+			sets[imgFormat.Name] = ImageSet{Images: urls, Srcset: responsive.Srcset(urls)}
+		}
+
+		log.Info("image set saved", slog.Int("widths", len(resized)), slog.Int("formats", len(formats)))
+
+		primary, _ := format.Lookup(formats[0])
+		set := sets[primary.Name]
+		if len(formats) < 2 {
+			sets = nil
+		}
+
+		responseOK(w, r, set, sets)
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, set ImageSet, formats map[string]ImageSet) {
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, Response{
+		Response: response.OK(),
+		ImageSet: set,
+		Formats:  formats,
+	})
+}
@@ -0,0 +1,52 @@
+package responsive_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"net/http"
+	"net/http/httptest"
+	"online-photo-editor/internal/http-server/handlers/image/processor/mocks"
+	"online-photo-editor/internal/http-server/handlers/image/responsive"
+	"online-photo-editor/internal/lib/logger/handlers/slogdiscard"
+	"testing"
+
+	"github.com/go-chi/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHandler_Responsive_DedupesFormatAliases(t *testing.T) {
+	mockProcessor := new(mocks.ImageProcessor)
+	logger := slogdiscard.NewDiscardLogger()
+	handler := responsive.New(logger, mockProcessor)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"image_name": "photo.png",
+		"widths":     []int{50},
+		"formats":    []string{"jpg", "jpeg"},
+	})
+	assert.NoError(t, err)
+
+	mockProcessor.On("LoadImage", "photo.png").Return(image.NewGray(image.Rect(0, 0, 100, 100)), nil)
+	mockProcessor.On("GenerateName", "w50", ".jpg").Return("w50.jpg", nil).Once()
+	mockProcessor.On("SaveImage", mock.Anything, "w50.jpg").Return("/images/w50.jpg", nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/image/responsive", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	resp := w.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	mockProcessor.AssertExpectations(t)
+
+	var result responsive.Response
+	err = render.DecodeJSON(resp.Body, &result)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{50: "/images/w50.jpg"}, result.Images)
+	assert.Nil(t, result.Formats)
+}
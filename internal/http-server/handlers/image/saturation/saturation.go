@@ -79,7 +79,7 @@ func New(log *slog.Logger, imgSaturation processor.ImageProcessor) http.HandlerF
 			return
 		}
 
-		imgName, err := imgSaturation.GenerateName("proc", filepath.Ext(req.ImageName))
+		imgName, err := imgSaturation.GenerateName("proc", processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName)))
 		if err != nil {
 			log.Error("failed to generate name", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
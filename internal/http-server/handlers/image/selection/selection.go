@@ -0,0 +1,106 @@
+package selection
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"online-photo-editor/internal/http-server/handlers/image/processor"
+	"online-photo-editor/internal/lib/api/response"
+	"online-photo-editor/internal/lib/api/selection"
+	"online-photo-editor/internal/lib/logger/sl"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+)
+
+type Request struct {
+	selection.SelectionParams
+	ImageName string `json:"image_name" validate:"required,max=100"`
+}
+
+type Response struct {
+	response.Response
+	MaskUrl string `json:"mask_url"`
+}
+
+func New(log *slog.Logger, imgSelector processor.ImageProcessor) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "handlers.img.selection.New"
+
+		log.With(
+			slog.String("op", op),
+			slog.String("request_id", middleware.GetReqID(r.Context())),
+		)
+
+		var req Request
+
+		err := render.DecodeJSON(r.Body, &req)
+		if errors.Is(err, io.EOF) {
+			log.Error("request body is empty")
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("empty request"))
+
+			return
+		}
+
+		if err != nil {
+			log.Error("failed to decode request body", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error("failed to decode request"))
+
+			return
+		}
+
+		if !response.Validation(log, w, r, req, http.StatusBadRequest) {
+			return
+		}
+
+		log.Info("request body decoded", slog.Any("request", req))
+
+		inputImg, err := imgSelector.LoadImage(req.ImageName)
+		if err != nil {
+			log.Error("failed to load image", sl.Err(err))
+			render.Status(r, http.StatusNotFound)
+			render.JSON(w, r, response.Error("failed to load image"))
+			return
+		}
+
+		maskImg, err := req.SelectionParams.SelectImage(inputImg)
+		if err != nil {
+			log.Error("failed to select image", sl.Err(err))
+			render.Status(r, http.StatusBadRequest)
+			render.JSON(w, r, response.Error(fmt.Sprintf("failed to select image: %v", err)))
+			return
+		}
+
+		maskName, err := imgSelector.GenerateName("mask", ".png")
+		if err != nil {
+			log.Error("failed to generate name", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to generate name"))
+			return
+		}
+
+		maskUrl, err := imgSelector.SaveImage(maskImg, maskName)
+		if err != nil {
+			log.Error("failed to save mask", sl.Err(err))
+			render.Status(r, http.StatusInternalServerError)
+			render.JSON(w, r, response.Error("failed to save mask"))
+			return
+		}
+
+		log.Info("mask saved", slog.String("mask url", maskUrl))
+
+		responseOK(w, r, maskUrl)
+	}
+}
+
+func responseOK(w http.ResponseWriter, r *http.Request, maskUrl string) {
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, Response{
+		Response: response.OK(),
+		MaskUrl:  maskUrl,
+	})
+}
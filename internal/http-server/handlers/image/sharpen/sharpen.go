@@ -79,7 +79,7 @@ func New(log *slog.Logger, imgSharpen processor.ImageProcessor) http.HandlerFunc
 			return
 		}
 
-		imgName, err := imgSharpen.GenerateName("proc", filepath.Ext(req.ImageName))
+		imgName, err := imgSharpen.GenerateName("proc", processor.OutputFormat(w, r, inputImg, filepath.Ext(req.ImageName)))
 		if err != nil {
 			log.Error("failed to generate name", sl.Err(err))
 			render.Status(r, http.StatusInternalServerError)
@@ -0,0 +1,78 @@
+package animate
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+)
+
+const perFramePalette = "per_frame"
+
+// MaxPixels bounds the pixels of all frames together. Every frame is held in
+// memory twice, as decoded and as paletted, until the GIF is written.
+const MaxPixels = 100_000_000
+
+type AnimateParams struct {
+	Delay     int    `json:"delay" validate:"min=0,max=655350"`
+	LoopCount int    `json:"loop_count" validate:"min=-1,max=65535"`
+	Palette   string `json:"palette" validate:"omitempty,oneof=shared per_frame"`
+}
+
+// AnimateImages assembles frames into an animated GIF. Delays are given in
+// milliseconds per frame; a zero delay falls back to params.Delay.
+func (params *AnimateParams) AnimateImages(frames []image.Image, delays []int) (*gif.GIF, error) {
+	const op = "api.animate.AnimateImages"
+
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("%s: at least one frame is required", op)
+	}
+	if len(frames) != len(delays) {
+		return nil, fmt.Errorf("%s: mismatched frame and delay count", op)
+	}
+
+	var width, height, pixels int
+	for _, frame := range frames {
+		b := frame.Bounds()
+		width = max(width, b.Dx())
+		height = max(height, b.Dy())
+		pixels += b.Dx() * b.Dy()
+	}
+	if pixels > MaxPixels {
+		return nil, fmt.Errorf("%s: frames exceed %d pixels in total, resize them first", op, MaxPixels)
+	}
+
+	anim := &gif.GIF{
+		LoopCount: params.LoopCount,
+		Config:    image.Config{Width: width, Height: height},
+	}
+
+	var shared color.Palette
+	if params.Palette != perFramePalette {
+		shared = quantize(frames, 256)
+		anim.Config.ColorModel = shared
+	}
+
+	for i, frame := range frames {
+		pal := shared
+		if pal == nil {
+			pal = quantize(frames[i:i+1], 256)
+		}
+
+		b := frame.Bounds()
+		dst := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), pal)
+		draw.FloydSteinberg.Draw(dst, dst.Bounds(), frame, b.Min)
+
+		delay := delays[i]
+		if delay == 0 {
+			delay = params.Delay
+		}
+
+		anim.Image = append(anim.Image, dst)
+		anim.Delay = append(anim.Delay, delay/10)
+		anim.Disposal = append(anim.Disposal, gif.DisposalBackground)
+	}
+
+	return anim, nil
+}
@@ -0,0 +1,114 @@
+package animate_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/animate"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// blank is an image with bounds but no pixel data.
+type blank struct {
+	image.Rectangle
+}
+
+func (b blank) ColorModel() color.Model { return color.NRGBAModel }
+func (b blank) Bounds() image.Rectangle { return b.Rectangle }
+func (b blank) At(x, y int) color.Color { return color.NRGBA{} }
+
+func filled(w, h int, c color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestAnimateImages_DelaysInCentiseconds(t *testing.T) {
+	frames := []image.Image{filled(4, 4, color.NRGBA{R: 255, A: 255}), filled(4, 4, color.NRGBA{B: 255, A: 255})}
+
+	anim, err := (&animate.AnimateParams{Delay: 100, LoopCount: -1}).AnimateImages(frames, []int{0, 250})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{10, 25}, anim.Delay)
+	assert.Equal(t, -1, anim.LoopCount)
+}
+
+func TestAnimateImages_MixedSizes(t *testing.T) {
+	frames := []image.Image{filled(10, 10, color.NRGBA{A: 255}), filled(20, 5, color.NRGBA{A: 255})}
+
+	anim, err := (&animate.AnimateParams{}).AnimateImages(frames, []int{0, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, anim.Config.Width)
+	assert.Equal(t, 10, anim.Config.Height)
+	assert.Equal(t, image.Rect(0, 0, 10, 10), anim.Image[0].Rect)
+	assert.Equal(t, image.Rect(0, 0, 20, 5), anim.Image[1].Rect)
+}
+
+func TestAnimateImages_KeepsFewColorsExactly(t *testing.T) {
+	colors := []color.NRGBA{
+		{R: 255, A: 255},
+		{G: 200, A: 255},
+		{B: 100, A: 255},
+		{R: 30, G: 60, B: 90, A: 255},
+	}
+	frame := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			frame.SetNRGBA(x, y, colors[(x/2+y)%len(colors)])
+		}
+	}
+	// A transparent corner gets the transparent palette entry.
+	frame.SetNRGBA(0, 0, color.NRGBA{})
+
+	for _, palette := range []string{"shared", "per_frame"} {
+		anim, err := (&animate.AnimateParams{Palette: palette}).AnimateImages([]image.Image{frame}, []int{0})
+		assert.NoError(t, err)
+
+		dst := anim.Image[0]
+		assert.LessOrEqual(t, len(dst.Palette), 256)
+		for y := 0; y < 8; y++ {
+			for x := 0; x < 8; x++ {
+				want := frame.NRGBAAt(x, y)
+				got := color.NRGBAModel.Convert(dst.At(x, y)).(color.NRGBA)
+				if want.A == 0 {
+					assert.Zero(t, got.A, "%s: pixel (%d, %d)", palette, x, y)
+					continue
+				}
+				assert.Equal(t, want, got, "%s: pixel (%d, %d)", palette, x, y)
+			}
+		}
+	}
+}
+
+func TestAnimateImages_SharedPalette(t *testing.T) {
+	frames := []image.Image{filled(4, 4, color.NRGBA{R: 255, A: 255}), filled(4, 4, color.NRGBA{B: 255, A: 255})}
+
+	shared, err := (&animate.AnimateParams{}).AnimateImages(frames, []int{0, 0})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.Image[0].Palette, shared.Image[1].Palette)
+	assert.Len(t, shared.Image[0].Palette, 2)
+
+	perFrame, err := (&animate.AnimateParams{Palette: "per_frame"}).AnimateImages(frames, []int{0, 0})
+	assert.NoError(t, err)
+	assert.NotEqual(t, perFrame.Image[0].Palette, perFrame.Image[1].Palette)
+}
+
+func TestAnimateImages_RejectsTooManyPixels(t *testing.T) {
+	frames := []image.Image{blank{image.Rect(0, 0, 8000, 8000)}, blank{image.Rect(0, 0, 8000, 8000)}}
+
+	_, err := (&animate.AnimateParams{}).AnimateImages(frames, []int{0, 0})
+	assert.Error(t, err)
+}
+
+func TestAnimateImages_Errors(t *testing.T) {
+	_, err := (&animate.AnimateParams{}).AnimateImages(nil, nil)
+	assert.Error(t, err)
+
+	_, err = (&animate.AnimateParams{}).AnimateImages([]image.Image{filled(1, 1, color.NRGBA{})}, []int{0, 0})
+	assert.Error(t, err)
+}
@@ -0,0 +1,138 @@
+package animate
+
+import (
+	"image"
+	"image/color"
+	"slices"
+)
+
+// maxSamples bounds the number of pixels fed into the quantizer so large
+// sequences do not blow up memory.
+const maxSamples = 1 << 18
+
+type colorBox struct {
+	colors []color.NRGBA
+}
+
+// quantize builds a palette of at most n colors for the given images using
+// median cut. A fully transparent entry is reserved when any pixel is
+// transparent.
+func quantize(imgs []image.Image, n int) color.Palette {
+	var total int
+	for _, img := range imgs {
+		total += img.Bounds().Dx() * img.Bounds().Dy()
+	}
+
+	step := 1
+	if total > maxSamples {
+		step = total / maxSamples
+	}
+
+	var samples []color.NRGBA
+	transparent := false
+	i := 0
+	for _, img := range imgs {
+		b := img.Bounds()
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			for x := b.Min.X; x < b.Max.X; x++ {
+				i++
+				if i%step != 0 {
+					continue
+				}
+				c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+				if c.A < 128 {
+					transparent = true
+					continue
+				}
+				c.A = 255
+				samples = append(samples, c)
+			}
+		}
+	}
+
+	if transparent {
+		n--
+	}
+
+	boxes := []colorBox{{colors: samples}}
+	for len(boxes) < n {
+		idx, ch := -1, 0
+		widest := uint8(0)
+		for j, box := range boxes {
+			if len(box.colors) < 2 {
+				continue
+			}
+			c, r := box.widestChannel()
+			if idx == -1 || r > widest {
+				idx, ch, widest = j, c, r
+			}
+		}
+		if idx == -1 || widest == 0 {
+			break
+		}
+
+		box := boxes[idx]
+		slices.SortFunc(box.colors, func(a, b color.NRGBA) int {
+			return int(channel(a, ch)) - int(channel(b, ch))
+		})
+		mid := len(box.colors) / 2
+		boxes[idx] = colorBox{colors: box.colors[:mid]}
+		boxes = append(boxes, colorBox{colors: box.colors[mid:]})
+	}
+
+	pal := make(color.Palette, 0, n+1)
+	for _, box := range boxes {
+		if len(box.colors) > 0 {
+			pal = append(pal, box.average())
+		}
+	}
+	if transparent || len(pal) == 0 {
+		pal = append(pal, color.NRGBA{})
+	}
+
+	return pal
+}
+
+func (box colorBox) widestChannel() (int, uint8) {
+	lo := [3]uint8{255, 255, 255}
+	var hi [3]uint8
+	for _, c := range box.colors {
+		for ch := 0; ch < 3; ch++ {
+			v := channel(c, ch)
+			lo[ch] = min(lo[ch], v)
+			hi[ch] = max(hi[ch], v)
+		}
+	}
+
+	best := 0
+	for ch := 1; ch < 3; ch++ {
+		if hi[ch]-lo[ch] > hi[best]-lo[best] {
+			best = ch
+		}
+	}
+
+	return best, hi[best] - lo[best]
+}
+
+func (box colorBox) average() color.NRGBA {
+	var r, g, b int
+	for _, c := range box.colors {
+		r += int(c.R)
+		g += int(c.G)
+		b += int(c.B)
+	}
+	n := len(box.colors)
+
+	return color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: 255}
+}
+
+func channel(c color.NRGBA, ch int) uint8 {
+	switch ch {
+	case 0:
+		return c.R
+	case 1:
+		return c.G
+	default:
+		return c.B
+	}
+}
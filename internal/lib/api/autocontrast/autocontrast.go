@@ -0,0 +1,37 @@
+package autocontrast
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/histogram"
+
+	"github.com/disintegration/imaging"
+)
+
+type AutoContrastParams struct {
+	ClipLow  float64 `json:"clip_low" validate:"min=0,max=50"`
+	ClipHigh float64 `json:"clip_high" validate:"min=0,max=50"`
+}
+
+// AutoContrastImage stretches the luma range of the image to the full range
+// and applies the same mapping to all channels, which keeps the hues.
+// ClipLow and ClipHigh are the percentages of darkest and brightest pixels
+// that may be clipped.
+func (params *AutoContrastParams) AutoContrastImage(img image.Image) (image.Image, error) {
+	src := imaging.Clone(img)
+
+	var hist histogram.Histogram
+	for i := 0; i < len(src.Pix); i += 4 {
+		if src.Pix[i+3] == 0 {
+			continue
+		}
+		hist[colors.Clamp8(colors.Luma(float64(src.Pix[i]), float64(src.Pix[i+1]), float64(src.Pix[i+2])))]++
+	}
+
+	lut := hist.Stretch(params.ClipLow, params.ClipHigh)
+
+	return imaging.AdjustFunc(src, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[c.R], G: lut[c.G], B: lut[c.B], A: c.A}
+	}), nil
+}
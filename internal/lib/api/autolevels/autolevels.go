@@ -0,0 +1,40 @@
+package autolevels
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/histogram"
+
+	"github.com/disintegration/imaging"
+)
+
+type AutoLevelsParams struct {
+	ClipLow  float64 `json:"clip_low" validate:"min=0,max=50"`
+	ClipHigh float64 `json:"clip_high" validate:"min=0,max=50"`
+}
+
+// AutoLevelsImage stretches every color channel on its own to the full
+// range, which also removes color casts. ClipLow and ClipHigh are the
+// percentages of darkest and brightest values that may be clipped.
+func (params *AutoLevelsParams) AutoLevelsImage(img image.Image) (image.Image, error) {
+	src := imaging.Clone(img)
+
+	var hists [3]histogram.Histogram
+	for i := 0; i < len(src.Pix); i += 4 {
+		if src.Pix[i+3] == 0 {
+			continue
+		}
+		for c := range hists {
+			hists[c][src.Pix[i+c]]++
+		}
+	}
+
+	var luts [3][256]uint8
+	for c := range luts {
+		luts[c] = hists[c].Stretch(params.ClipLow, params.ClipHigh)
+	}
+
+	return imaging.AdjustFunc(src, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: luts[0][c.R], G: luts[1][c.G], B: luts[2][c.B], A: c.A}
+	}), nil
+}
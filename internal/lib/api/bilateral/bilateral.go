@@ -0,0 +1,70 @@
+package bilateral
+
+import (
+	"image"
+	"math"
+	"online-photo-editor/internal/lib/parallel"
+
+	"github.com/disintegration/imaging"
+)
+
+type BilateralParams struct {
+	SigmaSpatial float64 `json:"sigma_spatial" validate:"required,min=0.5,max=10"`
+	SigmaRange   float64 `json:"sigma_range" validate:"required,min=1,max=255"`
+}
+
+// BilateralImage smooths the image with weights that fall off both with the
+// distance to the pixel (SigmaSpatial, in pixels) and with the difference in
+// color (SigmaRange), so edges with a large color step are kept. Alpha is
+// kept.
+func (params *BilateralParams) BilateralImage(img image.Image) (image.Image, error) {
+	src := imaging.Clone(img)
+	dst := imaging.Clone(src)
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	r := int(math.Ceil(2 * params.SigmaSpatial))
+	size := 2*r + 1
+
+	spatial := make([]float64, size*size)
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			spatial[(dy+r)*size+dx+r] = math.Exp(-float64(dx*dx+dy*dy) / (2 * params.SigmaSpatial * params.SigmaSpatial))
+		}
+	}
+
+	// rangeWeight is indexed by the squared color distance summed over the
+	// three channels.
+	rangeWeight := make([]float64, 3*255*255+1)
+	for d := range rangeWeight {
+		rangeWeight[d] = math.Exp(-float64(d) / (2 * params.SigmaRange * params.SigmaRange))
+	}
+
+	parallel.Rows(h, func(y int) {
+		for x := 0; x < w; x++ {
+			i := y*src.Stride + x*4
+			r0, g0, b0 := int(src.Pix[i]), int(src.Pix[i+1]), int(src.Pix[i+2])
+
+			var sr, sg, sb, sw float64
+			for dy := max(-r, -y); dy <= min(r, h-1-y); dy++ {
+				row := (y+dy)*src.Stride + x*4
+				center := (dy+r)*size + r
+				for dx := max(-r, -x); dx <= min(r, w-1-x); dx++ {
+					j := row + dx*4
+					cr, cg, cb := int(src.Pix[j]), int(src.Pix[j+1]), int(src.Pix[j+2])
+					d := (cr-r0)*(cr-r0) + (cg-g0)*(cg-g0) + (cb-b0)*(cb-b0)
+					wt := spatial[center+dx] * rangeWeight[d]
+					sr += wt * float64(cr)
+					sg += wt * float64(cg)
+					sb += wt * float64(cb)
+					sw += wt
+				}
+			}
+
+			dst.Pix[i] = uint8(math.Round(sr / sw))
+			dst.Pix[i+1] = uint8(math.Round(sg / sw))
+			dst.Pix[i+2] = uint8(math.Round(sb / sw))
+		}
+	})
+
+	return dst, nil
+}
@@ -0,0 +1,59 @@
+package border
+
+import (
+	"image"
+	"image/draw"
+	"online-photo-editor/internal/lib/api/pad"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type BorderParams struct {
+	Width int    `json:"width" validate:"required,min=1,max=1000"`
+	Color string `json:"color" validate:"omitempty,max=20"`
+	Inset bool   `json:"inset"`
+}
+
+// BorderImage frames img with a solid border. By default the canvas grows by
+// the border width on every side; with Inset the border is drawn over the
+// outer pixels of the image instead.
+func (params *BorderParams) BorderImage(img image.Image) (image.Image, error) {
+	c := "black"
+	if params.Color != "" {
+		c = params.Color
+	}
+
+	if !params.Inset {
+		padParams := pad.PadParams{
+			Top:    params.Width,
+			Right:  params.Width,
+			Bottom: params.Width,
+			Left:   params.Width,
+			Color:  c,
+		}
+
+		return padParams.PadImage(img)
+	}
+
+	fill, err := colors.Parse(c)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := imaging.Clone(img)
+	b := dst.Bounds()
+	w := params.Width
+	src := image.NewUniform(fill)
+
+	for _, rect := range []image.Rectangle{
+		image.Rect(b.Min.X, b.Min.Y, b.Max.X, b.Min.Y+w),
+		image.Rect(b.Min.X, b.Max.Y-w, b.Max.X, b.Max.Y),
+		image.Rect(b.Min.X, b.Min.Y+w, b.Min.X+w, b.Max.Y-w),
+		image.Rect(b.Max.X-w, b.Min.Y+w, b.Max.X, b.Max.Y-w),
+	} {
+		draw.Draw(dst, rect.Intersect(b), src, image.Point{}, draw.Over)
+	}
+
+	return dst, nil
+}
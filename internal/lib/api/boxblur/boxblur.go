@@ -0,0 +1,39 @@
+package boxblur
+
+import (
+	"image"
+	"online-photo-editor/internal/lib/convolution"
+
+	"github.com/disintegration/imaging"
+)
+
+type BoxBlurParams struct {
+	Radius int    `json:"radius" validate:"required,min=1,max=100"`
+	Edge   string `json:"edge" validate:"omitempty,oneof=extend wrap mirror zero"`
+}
+
+// BoxBlurImage averages every pixel with its neighbors in a square of
+// 2*Radius+1 pixels. Colors are weighted by alpha so transparent pixels do
+// not darken the result.
+func (params *BoxBlurParams) BoxBlurImage(img image.Image) (image.Image, error) {
+	planes := convolution.Split(imaging.Clone(img))
+	alpha := planes[3]
+
+	for c := 0; c < 3; c++ {
+		for i, a := range alpha.Pix {
+			planes[c].Pix[i] *= a / 255
+		}
+	}
+	for c := range planes {
+		planes[c] = planes[c].Box(params.Radius, params.Edge)
+	}
+	for c := 0; c < 3; c++ {
+		for i, a := range planes[3].Pix {
+			if a > 0 {
+				planes[c].Pix[i] *= 255 / a
+			}
+		}
+	}
+
+	return convolution.Merge(planes), nil
+}
@@ -0,0 +1,76 @@
+package boxblur_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/boxblur"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// step returns an 8 x 3 image whose left half is gray lo and right half is
+// gray hi, a vertical edge between x = 3 and x = 4.
+func step(lo, hi uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 8; x++ {
+			v := lo
+			if x >= 4 {
+				v = hi
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+// middle returns the gray values of the middle row, checking that the
+// pixels are gray and opaque.
+func middle(t *testing.T, img image.Image) []uint8 {
+	t.Helper()
+
+	var values []uint8
+	for x := 0; x < img.Bounds().Dx(); x++ {
+		c := color.NRGBAModel.Convert(img.At(x, 1)).(color.NRGBA)
+		require.Equal(t, color.NRGBA{R: c.R, G: c.R, B: c.R, A: 255}, c, "pixel %d", x)
+		values = append(values, c.R)
+	}
+
+	return values
+}
+
+func TestBoxBlurImage_StepEdge(t *testing.T) {
+	tests := []struct {
+		name   string
+		params boxblur.BoxBlurParams
+		want   []uint8
+	}{
+		{"radius 1", boxblur.BoxBlurParams{Radius: 1}, []uint8{0, 0, 0, 85, 170, 255, 255, 255}},
+		{"radius 2", boxblur.BoxBlurParams{Radius: 2}, []uint8{0, 0, 51, 102, 153, 204, 255, 255}},
+		{"wrap", boxblur.BoxBlurParams{Radius: 1, Edge: "wrap"}, []uint8{85, 0, 0, 85, 170, 255, 255, 170}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.params.BoxBlurImage(step(0, 255))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, middle(t, out))
+		})
+	}
+}
+
+func TestBoxBlurImage_TransparentPixelsDoNotDarken(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 255, A: 255})
+
+	params := boxblur.BoxBlurParams{Radius: 1}
+	out, err := params.BoxBlurImage(img)
+	require.NoError(t, err)
+
+	assert.Equal(t, color.NRGBA{R: 255, A: 170}, out.At(0, 0))
+	assert.Equal(t, color.NRGBA{R: 255, A: 85}, out.At(1, 0))
+	assert.Equal(t, color.NRGBA{}, out.At(2, 0))
+}
@@ -0,0 +1,88 @@
+package chromakey
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/parallel"
+
+	"github.com/disintegration/imaging"
+)
+
+// maxDistance is the Euclidean distance between black and white in RGB.
+var maxDistance = math.Sqrt(3) * 0xff
+
+type ChromaKeyParams struct {
+	Color     string  `json:"color" validate:"omitempty,max=20"`
+	Tolerance float64 `json:"tolerance" validate:"min=0,max=100"`
+	Softness  float64 `json:"softness" validate:"min=0,max=100"`
+	Spill     float64 `json:"spill" validate:"min=0,max=100"`
+}
+
+// ChromaKeyImage makes pixels close to the key color transparent. Color
+// defaults to the top-left pixel. Pixels within Tolerance percent of the key
+// color in RGB are removed and the next Softness percent fade in gradually.
+// Spill removes up to Spill percent of the key color's cast from the pixels
+// that remain, such as green reflections on the edges of the subject.
+func (params *ChromaKeyParams) ChromaKeyImage(img image.Image) (image.Image, error) {
+	const op = "api.chromakey.ChromaKeyImage"
+
+	src := imaging.Clone(img)
+	if src.Rect.Empty() {
+		return src, nil
+	}
+
+	key := src.NRGBAAt(0, 0)
+	if params.Color != "" {
+		var err error
+		if key, err = colors.Parse(params.Color); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	inner := params.Tolerance / 100 * maxDistance
+	outer := inner + params.Softness/100*maxDistance
+
+	// The cast of the key color is its deviation from gray. Removing a
+	// multiple of it keeps the sum of the channels, and so the brightness.
+	kr, kg, kb := float64(key.R), float64(key.G), float64(key.B)
+	mean := (kr + kg + kb) / 3
+	cast := [3]float64{kr - mean, kg - mean, kb - mean}
+	if norm := math.Sqrt(cast[0]*cast[0] + cast[1]*cast[1] + cast[2]*cast[2]); norm > 0 {
+		for c := range cast {
+			cast[c] /= norm
+		}
+	}
+	spill := params.Spill / 100
+
+	parallel.Rows(src.Rect.Dy(), func(y int) {
+		row := src.Pix[y*src.Stride : y*src.Stride+src.Rect.Dx()*4]
+		for i := 0; i < len(row); i += 4 {
+			r, g, b := float64(row[i]), float64(row[i+1]), float64(row[i+2])
+
+			d := math.Sqrt((r-kr)*(r-kr) + (g-kg)*(g-kg) + (b-kb)*(b-kb))
+			var keep float64
+			switch {
+			case d <= inner:
+				keep = 0
+			case d >= outer:
+				keep = 1
+			default:
+				keep = (d - inner) / (outer - inner)
+			}
+			row[i+3] = colors.Clamp8(float64(row[i+3]) * keep)
+
+			if spill > 0 {
+				m := (r + g + b) / 3
+				if s := (r-m)*cast[0] + (g-m)*cast[1] + (b-m)*cast[2]; s > 0 {
+					row[i] = colors.Clamp8(r - spill*s*cast[0])
+					row[i+1] = colors.Clamp8(g - spill*s*cast[1])
+					row[i+2] = colors.Clamp8(b - spill*s*cast[2])
+				}
+			}
+		}
+	})
+
+	return src, nil
+}
@@ -0,0 +1,116 @@
+package clahe
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/histogram"
+
+	"github.com/disintegration/imaging"
+)
+
+type ClaheParams struct {
+	Tiles     int     `json:"tiles" validate:"omitempty,min=2,max=64"`
+	ClipLimit float64 `json:"clip_limit" validate:"omitempty,min=1,max=40"`
+}
+
+// ClaheImage applies contrast-limited adaptive histogram equalization to the
+// luma of the image. The image is divided into a grid of Tiles x Tiles
+// (default 8), each tile is equalized with its histogram clipped at
+// ClipLimit (default 2) times the average bin height, and the tile mappings
+// are interpolated bilinearly between the tile centers.
+func (params *ClaheParams) ClaheImage(img image.Image) (image.Image, error) {
+	tiles, clipLimit := 8, 2.0
+	if params.Tiles != 0 {
+		tiles = params.Tiles
+	}
+	if params.ClipLimit != 0 {
+		clipLimit = params.ClipLimit
+	}
+
+	src := imaging.Clone(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+
+	luma := make([]uint8, w*h)
+	for i := range luma {
+		luma[i], _, _ = color.RGBToYCbCr(src.Pix[i*4], src.Pix[i*4+1], src.Pix[i*4+2])
+	}
+
+	tw := (w + min(tiles, w) - 1) / min(tiles, w)
+	th := (h + min(tiles, h) - 1) / min(tiles, h)
+	nx, ny := (w+tw-1)/tw, (h+th-1)/th
+
+	luts := make([][256]uint8, nx*ny)
+	for ty := 0; ty < ny; ty++ {
+		for tx := 0; tx < nx; tx++ {
+			var hist histogram.Histogram
+			for y := ty * th; y < min((ty+1)*th, h); y++ {
+				for x := tx * tw; x < min((tx+1)*tw, w); x++ {
+					if src.Pix[(y*w+x)*4+3] != 0 {
+						hist[luma[y*w+x]]++
+					}
+				}
+			}
+			hist.Clip(max(1, int(clipLimit*float64(hist.Total())/256)))
+			luts[ty*nx+tx] = cumulative(&hist)
+		}
+	}
+
+	// neighbors returns the two tiles around position p along an axis and
+	// the weight of the second one.
+	neighbors := func(p, size, n int) (int, int, float64) {
+		f := (float64(p)+0.5)/float64(size) - 0.5
+		i0 := int(math.Floor(f))
+		wt := f - float64(i0)
+		if i0 < 0 {
+			return 0, 0, 0
+		}
+		if i0 >= n-1 {
+			return n - 1, n - 1, 0
+		}
+		return i0, i0 + 1, wt
+	}
+
+	dst := image.NewNRGBA(src.Bounds())
+	for y := 0; y < h; y++ {
+		y0, y1, wy := neighbors(y, th, ny)
+		for x := 0; x < w; x++ {
+			x0, x1, wx := neighbors(x, tw, nx)
+			i := (y*w + x) * 4
+			v := luma[y*w+x]
+
+			top := float64(luts[y0*nx+x0][v])*(1-wx) + float64(luts[y0*nx+x1][v])*wx
+			bottom := float64(luts[y1*nx+x0][v])*(1-wx) + float64(luts[y1*nx+x1][v])*wx
+			mapped := uint8(math.Round(top*(1-wy) + bottom*wy))
+
+			_, cb, cr := color.RGBToYCbCr(src.Pix[i], src.Pix[i+1], src.Pix[i+2])
+			dst.Pix[i], dst.Pix[i+1], dst.Pix[i+2] = color.YCbCrToRGB(mapped, cb, cr)
+			dst.Pix[i+3] = src.Pix[i+3]
+		}
+	}
+
+	return dst, nil
+}
+
+// cumulative maps every value to its scaled cumulative frequency. Unlike a
+// full equalization the darkest value is not forced to 0, which keeps flat
+// tiles from turning black.
+func cumulative(hist *histogram.Histogram) [256]uint8 {
+	var lut [256]uint8
+
+	total := hist.Total()
+	if total == 0 {
+		for v := range lut {
+			lut[v] = uint8(v)
+		}
+		return lut
+	}
+
+	sum := 0
+	for v := range lut {
+		sum += hist[v]
+		lut[v] = uint8(math.Round(float64(sum) * 255 / float64(total)))
+	}
+
+	return lut
+}
@@ -0,0 +1,130 @@
+package clahe
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"online-photo-editor/internal/lib/histogram"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func noise(w, h int, seed int64) *image.NRGBA {
+	rng := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			// A gradient with noise, so every tile has its own histogram.
+			v := uint8(min(255, x*2+y+rng.Intn(40)))
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestClaheImage_UniformStaysUniform(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 48, 36))
+	for i := range img.Pix {
+		img.Pix[i] = 90
+	}
+
+	out, err := (&ClaheParams{Tiles: 4}).ClaheImage(img)
+	assert.NoError(t, err)
+
+	dst := out.(*image.NRGBA)
+	want := dst.NRGBAAt(0, 0)
+	for y := 0; y < 36; y++ {
+		for x := 0; x < 48; x++ {
+			if dst.NRGBAAt(x, y) != want {
+				t.Fatalf("pixel (%d, %d) is %v, want %v", x, y, dst.NRGBAAt(x, y), want)
+			}
+		}
+	}
+	assert.Equal(t, uint8(90), want.A)
+}
+
+// TestClaheImage_MatchesReference compares the result with a direct
+// implementation that looks up the four tiles around every pixel by the
+// distance to their centers.
+func TestClaheImage_MatchesReference(t *testing.T) {
+	const w, h, tiles, clip = 96, 64, 4, 2.0
+	img := noise(w, h, 1)
+
+	out, err := (&ClaheParams{Tiles: tiles, ClipLimit: clip}).ClaheImage(img)
+	assert.NoError(t, err)
+	dst := out.(*image.NRGBA)
+
+	tw, th := w/tiles, h/tiles
+	var luts [tiles][tiles][256]uint8
+	for ty := 0; ty < tiles; ty++ {
+		for tx := 0; tx < tiles; tx++ {
+			var hist histogram.Histogram
+			for y := ty * th; y < (ty+1)*th; y++ {
+				for x := tx * tw; x < (tx+1)*tw; x++ {
+					hist[img.Pix[img.PixOffset(x, y)]]++
+				}
+			}
+			hist.Clip(int(clip * float64(tw*th) / 256))
+			luts[ty][tx] = cumulative(&hist)
+		}
+	}
+
+	// around returns the tiles whose centers enclose p and the weight of
+	// the second one; outside the outer centers the nearest tile is used.
+	around := func(p, size int) (int, int, float64) {
+		center := func(i int) float64 { return float64(i*size) + float64(size)/2 - 0.5 }
+		pos := float64(p)
+		if pos <= center(0) {
+			return 0, 0, 0
+		}
+		for i := 0; i < tiles-1; i++ {
+			if pos < center(i+1) {
+				return i, i + 1, (pos - center(i)) / float64(size)
+			}
+		}
+		return tiles - 1, tiles - 1, 0
+	}
+
+	for y := 0; y < h; y++ {
+		y0, y1, wy := around(y, th)
+		for x := 0; x < w; x++ {
+			x0, x1, wx := around(x, tw)
+			v := img.Pix[img.PixOffset(x, y)]
+			top := float64(luts[y0][x0][v])*(1-wx) + float64(luts[y0][x1][v])*wx
+			bottom := float64(luts[y1][x0][v])*(1-wx) + float64(luts[y1][x1][v])*wx
+			want := math.Round(top*(1-wy) + bottom*wy)
+
+			// Allow for the rounding of a value that lands on .5.
+			if got := float64(dst.Pix[dst.PixOffset(x, y)]); math.Abs(got-want) > 1 {
+				t.Fatalf("pixel (%d, %d) is %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestClaheImage_StretchesLowContrast(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	img := image.NewNRGBA(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			v := uint8(100 + rng.Intn(20))
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	out, err := (&ClaheParams{Tiles: 4, ClipLimit: 4}).ClaheImage(img)
+	assert.NoError(t, err)
+	dst := out.(*image.NRGBA)
+
+	lo, hi := uint8(255), uint8(0)
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			v := dst.NRGBAAt(x, y).R
+			lo, hi = min(lo, v), max(hi, v)
+		}
+	}
+	assert.Greater(t, int(hi)-int(lo), 60)
+}
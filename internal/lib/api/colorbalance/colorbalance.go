@@ -0,0 +1,74 @@
+package colorbalance
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+// Shift moves a tonal range towards red, green or blue; negative values move
+// it towards cyan, magenta or yellow.
+type Shift struct {
+	Red   float64 `json:"red" validate:"min=-100,max=100"`
+	Green float64 `json:"green" validate:"min=-100,max=100"`
+	Blue  float64 `json:"blue" validate:"min=-100,max=100"`
+}
+
+type ColorBalanceParams struct {
+	Shadows            Shift `json:"shadows"`
+	Midtones           Shift `json:"midtones"`
+	Highlights         Shift `json:"highlights"`
+	PreserveLuminosity bool  `json:"preserve_luminosity"`
+}
+
+// ColorBalanceImage shifts the colors of shadows, midtones and highlights
+// separately. Each range is selected by the lightness of the pixel with soft
+// transitions between the ranges.
+func (params *ColorBalanceParams) ColorBalanceImage(img image.Image) (image.Image, error) {
+	shifts := func(pick func(s Shift) float64) [3]float64 {
+		return [3]float64{pick(params.Shadows) / 100, pick(params.Midtones) / 100, pick(params.Highlights) / 100}
+	}
+	red := shifts(func(s Shift) float64 { return s.Red })
+	green := shifts(func(s Shift) float64 { return s.Green })
+	blue := shifts(func(s Shift) float64 { return s.Blue })
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+		_, _, l := colors.RGBToHSL(r, g, b)
+		w := weights(l)
+
+		r = balance(r, w, red)
+		g = balance(g, w, green)
+		b = balance(b, w, blue)
+
+		if params.PreserveLuminosity {
+			h, s, _ := colors.RGBToHSL(r, g, b)
+			r, g, b = colors.HSLToRGB(h, s, l)
+		}
+
+		return color.NRGBA{R: colors.Clamp8(r * 255), G: colors.Clamp8(g * 255), B: colors.Clamp8(b * 255), A: c.A}
+	}), nil
+}
+
+// weights returns how much a pixel of lightness l belongs to the shadows,
+// midtones and highlights.
+func weights(l float64) [3]float64 {
+	const a, b, scale = 0.25, 0.333, 0.7
+
+	clamp := func(v float64) float64 {
+		return math.Min(math.Max(v, 0), 1)
+	}
+
+	return [3]float64{
+		clamp((l-b)/-a+0.5) * scale,
+		clamp((l-b)/a+0.5) * clamp((l+b-1)/-a+0.5) * scale,
+		clamp((l+b-1)/a+0.5) * scale,
+	}
+}
+
+func balance(v float64, w, shift [3]float64) float64 {
+	return math.Min(math.Max(v+w[0]*shift[0]+w[1]*shift[1]+w[2]*shift[2], 0), 1)
+}
@@ -0,0 +1,44 @@
+package colorize
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type ColorizeParams struct {
+	Color  string  `json:"color" validate:"required,max=20"`
+	Amount float64 `json:"amount" validate:"min=0,max=100"`
+}
+
+// ColorizeImage replaces the hue and saturation of every pixel with those of
+// the color, keeping the lightness, and blends the result with the original
+// by amount. An amount of 0 is treated as 100.
+func (params *ColorizeParams) ColorizeImage(img image.Image) (image.Image, error) {
+	target, err := colors.Parse(params.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := params.Amount / 100
+	if amount == 0 {
+		amount = 1
+	}
+
+	h, s, _ := colors.RGBToHSL(float64(target.R)/255, float64(target.G)/255, float64(target.B)/255)
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+		_, _, l := colors.RGBToHSL(r, g, b)
+		cr, cg, cb := colors.HSLToRGB(h, s, l)
+
+		return color.NRGBA{
+			R: colors.Clamp8((r + (cr-r)*amount) * 255),
+			G: colors.Clamp8((g + (cg-g)*amount) * 255),
+			B: colors.Clamp8((b + (cb-b)*amount) * 255),
+			A: c.A,
+		}
+	}), nil
+}
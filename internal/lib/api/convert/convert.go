@@ -1,9 +1,99 @@
 package convert
 
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/format"
+)
+
+var ErrAlphaLoss = errors.New("image has transparent pixels and the target format does not support alpha, specify a background color")
+
 type ConvertParams struct {
-	Format string `json:"format" validate:"required,lowercase,max=10"`
+	Format     string `json:"format" validate:"omitempty,max=10,image_format"`
+	Background string `json:"background" validate:"omitempty,max=20"`
+}
+
+// ConvertImage returns the target file extension and, when the target format
+// has no alpha channel, the image flattened onto the background color.
+func (params *ConvertParams) ConvertImage(img image.Image) (image.Image, string, error) {
+	const op = "api.convert.ConvertImage"
+
+	imgFormat, ok := format.Lookup(params.Format)
+	if !ok || !imgFormat.CanEncode() {
+		return nil, "", fmt.Errorf("%s: unsupported format %q", op, params.Format)
+	}
+
+	if imgFormat.Alpha || !HasAlpha(img) {
+		return img, imgFormat.Extension, nil
+	}
+
+	if params.Background == "" {
+		return nil, "", fmt.Errorf("%s: %w", op, ErrAlphaLoss)
+	}
+
+	bg, err := colors.Parse(params.Background)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if bg.A != 0xff {
+		return nil, "", fmt.Errorf("%s: background color must be opaque", op)
+	}
+
+	return Flatten(img, bg), imgFormat.Extension, nil
 }
 
-func (params *ConvertParams) ConvertImage() (string, error) {
-	return params.Format, nil
+// HasAlpha reports whether img contains any pixel that is not fully opaque.
+func HasAlpha(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Transparency reports whether img is opaque, has only fully transparent
+// pixels besides opaque ones, or has semi-transparent pixels.
+func Transparency(img image.Image) format.Transparency {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return format.Opaque
+	}
+
+	t := format.Opaque
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			switch _, _, _, a := img.At(x, y).RGBA(); a {
+			case 0xffff:
+			case 0:
+				t = format.Transparent
+			default:
+				return format.Translucent
+			}
+		}
+	}
+
+	return t
+}
+
+// Flatten composites img over a solid background and returns an opaque image.
+func Flatten(img image.Image, bg color.Color) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+
+	return dst
 }
@@ -0,0 +1,51 @@
+package convolve
+
+import (
+	"image"
+	"online-photo-editor/internal/lib/convolution"
+
+	"github.com/disintegration/imaging"
+)
+
+type ConvolveParams struct {
+	Kernel  [][]float64 `json:"kernel" validate:"required"`
+	Divisor float64     `json:"divisor"`
+	Bias    float64     `json:"bias" validate:"min=-255,max=255"`
+	Edge    string      `json:"edge" validate:"omitempty,oneof=extend wrap mirror zero"`
+	Alpha   bool        `json:"alpha"`
+}
+
+// ConvolveImage applies the kernel to the color channels, and to alpha if
+// Alpha is set. Every sum is divided by Divisor, which defaults to the sum
+// of the kernel (or 1 if that is 0), and Bias is added.
+func (params *ConvolveParams) ConvolveImage(img image.Image) (image.Image, error) {
+	if err := convolution.Validate(params.Kernel); err != nil {
+		return nil, err
+	}
+
+	divisor := params.Divisor
+	if divisor == 0 {
+		for _, row := range params.Kernel {
+			for _, k := range row {
+				divisor += k
+			}
+		}
+	}
+	if divisor == 0 {
+		divisor = 1
+	}
+
+	planes := convolution.Split(imaging.Clone(img))
+	channels := 3
+	if params.Alpha {
+		channels = 4
+	}
+
+	for c := 0; c < channels; c++ {
+		planes[c] = planes[c].Convolve(params.Kernel, params.Edge).Map(func(v float64) float64 {
+			return v/divisor + params.Bias
+		})
+	}
+
+	return convolution.Merge(planes), nil
+}
@@ -0,0 +1,94 @@
+package convolve_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/convolve"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func grays(alpha uint8, values ...uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(values), 1))
+	for x, v := range values {
+		img.SetNRGBA(x, 0, color.NRGBA{R: v, G: v, B: v, A: alpha})
+	}
+
+	return img
+}
+
+func row(img image.Image) (values, alphas []uint8) {
+	for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+		c := color.NRGBAModel.Convert(img.At(x, 0)).(color.NRGBA)
+		values = append(values, c.R)
+		alphas = append(alphas, c.A)
+	}
+
+	return values, alphas
+}
+
+var horizontal = [][]float64{
+	{0, 0, 0},
+	{1, 2, 1},
+	{0, 0, 0},
+}
+
+func TestConvolveImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		params convolve.ConvolveParams
+		src    []uint8
+		want   []uint8
+	}{
+		{"divisor defaults to the kernel sum", convolve.ConvolveParams{Kernel: horizontal},
+			[]uint8{0, 0, 100, 0, 0}, []uint8{0, 25, 50, 25, 0}},
+		{"divisor and bias", convolve.ConvolveParams{Kernel: horizontal, Divisor: 2, Bias: 10},
+			[]uint8{0, 0, 100, 0, 0}, []uint8{10, 60, 110, 60, 10}},
+		{"zero edge", convolve.ConvolveParams{Kernel: horizontal, Edge: "zero"},
+			[]uint8{100, 100, 100}, []uint8{75, 100, 75}},
+		{"wrap edge", convolve.ConvolveParams{Kernel: horizontal, Edge: "wrap"},
+			[]uint8{100, 0, 0, 0}, []uint8{50, 25, 0, 25}},
+		{"results are clamped", convolve.ConvolveParams{Kernel: horizontal, Divisor: 1, Bias: -50},
+			[]uint8{0, 200, 0, 0, 0}, []uint8{150, 255, 150, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.params.ConvolveImage(grays(255, tt.src...))
+			require.NoError(t, err)
+
+			values, _ := row(out)
+			assert.Equal(t, tt.want, values)
+		})
+	}
+}
+
+func TestConvolveImage_Alpha(t *testing.T) {
+	src := grays(100, 0, 0, 100, 0, 0)
+	src.SetNRGBA(2, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 200})
+
+	params := convolve.ConvolveParams{Kernel: horizontal, Edge: "zero"}
+	out, err := params.ConvolveImage(src)
+	require.NoError(t, err)
+	_, alphas := row(out)
+	assert.Equal(t, []uint8{100, 100, 200, 100, 100}, alphas)
+
+	params.Alpha = true
+	out, err = params.ConvolveImage(src)
+	require.NoError(t, err)
+	_, alphas = row(out)
+	assert.Equal(t, []uint8{75, 125, 150, 125, 75}, alphas)
+}
+
+func TestConvolveImage_InvalidKernel(t *testing.T) {
+	for _, kernel := range [][][]float64{
+		{{1, 1}, {1, 1}},
+		{{1, 1, 1}},
+	} {
+		params := convolve.ConvolveParams{Kernel: kernel}
+		_, err := params.ConvolveImage(grays(255, 0))
+		assert.Error(t, err, "%v", kernel)
+	}
+}
@@ -3,21 +3,115 @@ package crop
 import (
 	"fmt"
 	"image"
+	"math"
+	"online-photo-editor/internal/lib/aspect"
+	"online-photo-editor/internal/lib/gravity"
 
 	"github.com/disintegration/imaging"
 )
 
+const (
+	aspectMode  = "aspect"
+	marginsMode = "margins"
+
+	percentUnit = "percent"
+)
+
 type CropParams struct {
-	X      int `json:"x" validate:"required,min=0"`
-	Y      int `json:"y" validate:"required,min=0"`
-	Width  int `json:"width" validate:"required,min=1"`
-	Height int `json:"height" validate:"required,min=1"`
+	Mode    string  `json:"mode" validate:"omitempty,oneof=rect aspect margins"`
+	Unit    string  `json:"unit" validate:"omitempty,oneof=px percent"`
+	X       float64 `json:"x" validate:"min=0"`
+	Y       float64 `json:"y" validate:"min=0"`
+	Width   float64 `json:"width" validate:"min=0"`
+	Height  float64 `json:"height" validate:"min=0"`
+	Aspect  string  `json:"aspect" validate:"omitempty,max=20"`
+	Gravity string  `json:"gravity" validate:"omitempty,oneof=center north south east west northeast northwest southeast southwest"`
+	Top     float64 `json:"top" validate:"min=0"`
+	Right   float64 `json:"right" validate:"min=0"`
+	Bottom  float64 `json:"bottom" validate:"min=0"`
+	Left    float64 `json:"left" validate:"min=0"`
 }
 
-func (params *CropParams) validate(img image.Image) error {
+// rect resolves the params into an absolute crop rectangle for img.
+func (params *CropParams) rect(img image.Image) (image.Rectangle, error) {
+	const op = "api.crop.rect"
+
+	b := img.Bounds()
+
+	// toPx converts a horizontal or vertical value into pixels.
+	toPx := func(v float64, size int) (int, error) {
+		if params.Unit != percentUnit {
+			return int(math.Round(v)), nil
+		}
+		if v > 100 {
+			return 0, fmt.Errorf("%s: percentage must not exceed 100", op)
+		}
+		return int(math.Round(v * float64(size) / 100)), nil
+	}
+
+	switch params.Mode {
+	case aspectMode:
+		if params.Aspect == "" {
+			return image.Rectangle{}, fmt.Errorf("%s: aspect must be set in aspect mode", op)
+		}
+		ratio, err := aspect.Parse(params.Aspect)
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+		anchor, err := gravity.Parse(params.Gravity)
+		if err != nil {
+			return image.Rectangle{}, err
+		}
+
+		w, h := aspect.Fit(b.Dx(), b.Dy(), ratio)
+		pt := gravity.Point(b, w, h, anchor)
+
+		return image.Rect(pt.X, pt.Y, pt.X+w, pt.Y+h), nil
+	case marginsMode:
+		var margins [4]int
+		for i, m := range []struct {
+			value float64
+			size  int
+		}{{params.Top, b.Dy()}, {params.Right, b.Dx()}, {params.Bottom, b.Dy()}, {params.Left, b.Dx()}} {
+			px, err := toPx(m.value, m.size)
+			if err != nil {
+				return image.Rectangle{}, err
+			}
+			margins[i] = px
+		}
+
+		// image.Rect swaps inverted corners, so overlapping margins have to
+		// be caught before building the rectangle.
+		if margins[1]+margins[3] >= b.Dx() || margins[0]+margins[2] >= b.Dy() {
+			return image.Rectangle{}, fmt.Errorf("%s: margins leave nothing of the image", op)
+		}
+
+		return image.Rect(b.Min.X+margins[3], b.Min.Y+margins[0], b.Max.X-margins[1], b.Max.Y-margins[2]), nil
+	default:
+		var coords [4]int
+		for i, c := range []struct {
+			value float64
+			size  int
+		}{{params.X, b.Dx()}, {params.Y, b.Dy()}, {params.Width, b.Dx()}, {params.Height, b.Dy()}} {
+			px, err := toPx(c.value, c.size)
+			if err != nil {
+				return image.Rectangle{}, err
+			}
+			coords[i] = px
+		}
+
+		if coords[2] < 1 || coords[3] < 1 {
+			return image.Rectangle{}, fmt.Errorf("%s: width and height must be set in rect mode", op)
+		}
+
+		return image.Rect(coords[0], coords[1], coords[0]+coords[2], coords[1]+coords[3]).Add(b.Min), nil
+	}
+}
+
+func (params *CropParams) validate(img image.Image, rect image.Rectangle) error {
 	const op = "api.crop.validate"
 
-	if params.X+params.Width > img.Bounds().Max.X || params.Y+params.Height > img.Bounds().Max.Y {
+	if !rect.In(img.Bounds()) {
 		return fmt.Errorf("%s crop area exceeds image boundaries", op)
 	}
 
@@ -25,10 +119,14 @@ func (params *CropParams) validate(img image.Image) error {
 }
 
 func (params *CropParams) CropImage(img image.Image) (image.Image, error) {
-	if err := params.validate(img); err != nil {
+	rect, err := params.rect(img)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := params.validate(img, rect); err != nil {
 		return nil, err
 	}
 
-	rect := image.Rect(params.X, params.Y, params.X+params.Width, params.Y+params.Height)
 	return imaging.Crop(img, rect), nil
 }
@@ -0,0 +1,32 @@
+package crop_test
+
+import (
+	"image"
+	"online-photo-editor/internal/lib/api/crop"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCropImage_Margins(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+
+	params := crop.CropParams{Mode: "margins", Top: 5, Right: 10, Bottom: 15, Left: 20}
+	out, err := params.CropImage(img)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 70, 30), out.Bounds())
+}
+
+func TestCropImage_OverlappingMargins(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 100, 50))
+
+	for _, params := range []crop.CropParams{
+		{Mode: "margins", Left: 60, Right: 60},
+		{Mode: "margins", Left: 50, Right: 50},
+		{Mode: "margins", Top: 40, Bottom: 30},
+		{Mode: "margins", Unit: "percent", Top: 70, Bottom: 70},
+	} {
+		_, err := params.CropImage(img)
+		assert.ErrorContains(t, err, "margins leave nothing of the image", "%+v", params)
+	}
+}
@@ -0,0 +1,144 @@
+package curves
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+	"slices"
+
+	"github.com/disintegration/imaging"
+)
+
+type Point struct {
+	X float64 `json:"x" validate:"min=0,max=255"`
+	Y float64 `json:"y" validate:"min=0,max=255"`
+}
+
+// CurvesParams holds control points for the master curve, applied to all
+// color channels, and for the individual channels, applied before it.
+type CurvesParams struct {
+	RGB   []Point `json:"rgb" validate:"omitempty,min=2,max=32,dive"`
+	Red   []Point `json:"red" validate:"omitempty,min=2,max=32,dive"`
+	Green []Point `json:"green" validate:"omitempty,min=2,max=32,dive"`
+	Blue  []Point `json:"blue" validate:"omitempty,min=2,max=32,dive"`
+}
+
+// CurvesImage remaps every channel through curves interpolated from the
+// control points with a monotone cubic spline, so the curve never overshoots
+// between two points.
+func (params *CurvesParams) CurvesImage(img image.Image) (image.Image, error) {
+	const op = "api.curves.CurvesImage"
+
+	if params.RGB == nil && params.Red == nil && params.Green == nil && params.Blue == nil {
+		return nil, fmt.Errorf("%s: at least one curve must be set", op)
+	}
+
+	master, err := lut(params.RGB)
+	if err != nil {
+		return nil, err
+	}
+
+	var luts [3][256]uint8
+	for i, points := range [][]Point{params.Red, params.Green, params.Blue} {
+		channel, err := lut(points)
+		if err != nil {
+			return nil, err
+		}
+		for v := range channel {
+			luts[i][v] = master[channel[v]]
+		}
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: luts[0][c.R], G: luts[1][c.G], B: luts[2][c.B], A: c.A}
+	}), nil
+}
+
+// lut samples the curve through points at every 8-bit value. Without points
+// the curve is the identity; outside the first and last point it is flat.
+func lut(points []Point) ([256]uint8, error) {
+	const op = "api.curves.lut"
+
+	var table [256]uint8
+	if len(points) == 0 {
+		for v := range table {
+			table[v] = uint8(v)
+		}
+		return table, nil
+	}
+
+	points = slices.Clone(points)
+	slices.SortFunc(points, func(a, b Point) int {
+		return cmp.Compare(a.X, b.X)
+	})
+	for i := 1; i < len(points); i++ {
+		if points[i].X == points[i-1].X {
+			return table, fmt.Errorf("%s: control points must have distinct x values", op)
+		}
+	}
+
+	tangents := monotoneTangents(points)
+	last := len(points) - 1
+
+	k := 0
+	for v := range table {
+		x := float64(v)
+		switch {
+		case x <= points[0].X:
+			table[v] = colors.Clamp8(points[0].Y)
+		case x >= points[last].X:
+			table[v] = colors.Clamp8(points[last].Y)
+		default:
+			for points[k+1].X < x {
+				k++
+			}
+			table[v] = colors.Clamp8(hermite(points[k], points[k+1], tangents[k], tangents[k+1], x))
+		}
+	}
+
+	return table, nil
+}
+
+// monotoneTangents computes Fritsch-Carlson tangents, which keep the spline
+// monotone wherever the control points are.
+func monotoneTangents(points []Point) []float64 {
+	n := len(points)
+	slopes := make([]float64, n-1)
+	for i := range slopes {
+		slopes[i] = (points[i+1].Y - points[i].Y) / (points[i+1].X - points[i].X)
+	}
+
+	tangents := make([]float64, n)
+	tangents[0], tangents[n-1] = slopes[0], slopes[n-2]
+	for i := 1; i < n-1; i++ {
+		if slopes[i-1]*slopes[i] <= 0 {
+			continue
+		}
+		tangents[i] = (slopes[i-1] + slopes[i]) / 2
+	}
+
+	for i, s := range slopes {
+		if s == 0 {
+			tangents[i], tangents[i+1] = 0, 0
+			continue
+		}
+		a, b := tangents[i]/s, tangents[i+1]/s
+		if h := math.Hypot(a, b); h > 3 {
+			tangents[i] = 3 * a / h * s
+			tangents[i+1] = 3 * b / h * s
+		}
+	}
+
+	return tangents
+}
+
+func hermite(p0, p1 Point, m0, m1, x float64) float64 {
+	h := p1.X - p0.X
+	t := (x - p0.X) / h
+	t2, t3 := t*t, t*t*t
+
+	return (2*t3-3*t2+1)*p0.Y + (t3-2*t2+t)*h*m0 + (-2*t3+3*t2)*p1.Y + (t3-t2)*h*m1
+}
@@ -0,0 +1,82 @@
+package curves
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLUT_PassesThroughPoints(t *testing.T) {
+	points := []Point{{X: 0, Y: 0}, {X: 64, Y: 90}, {X: 128, Y: 140}, {X: 255, Y: 255}}
+
+	table, err := lut(points)
+	assert.NoError(t, err)
+	for _, p := range points {
+		assert.Equal(t, uint8(p.Y), table[int(p.X)])
+	}
+}
+
+func TestLUT_Monotone(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 200; i++ {
+		// Random increasing points, including steep steps and flat runs
+		// that make ordinary cubic splines overshoot.
+		n := 2 + rng.Intn(8)
+		points := make([]Point, n)
+		x, y := 0.0, 0.0
+		for j := range points {
+			x += 1 + float64(rng.Intn(255/n))
+			if rng.Intn(3) > 0 {
+				y = math.Min(255, y+float64(rng.Intn(120)))
+			}
+			points[j] = Point{X: x, Y: y}
+		}
+
+		table, err := lut(points)
+		assert.NoError(t, err)
+		for v := 1; v < len(table); v++ {
+			if table[v] < table[v-1] {
+				t.Fatalf("curve through %v decreases at %d: %d < %d", points, v, table[v], table[v-1])
+			}
+		}
+	}
+}
+
+func TestLUT_NoOvershoot(t *testing.T) {
+	// Between two points the curve stays within their values, even where
+	// the data changes direction.
+	points := []Point{{X: 0, Y: 0}, {X: 60, Y: 200}, {X: 120, Y: 40}, {X: 180, Y: 60}, {X: 255, Y: 255}}
+
+	table, err := lut(points)
+	assert.NoError(t, err)
+	for k := 0; k+1 < len(points); k++ {
+		lo := math.Min(points[k].Y, points[k+1].Y)
+		hi := math.Max(points[k].Y, points[k+1].Y)
+		for v := int(points[k].X); v <= int(points[k+1].X); v++ {
+			assert.True(t, float64(table[v]) >= lo && float64(table[v]) <= hi, "value %d at %d outside [%v, %v]", table[v], v, lo, hi)
+		}
+	}
+}
+
+func TestLUT_DuplicateX(t *testing.T) {
+	_, err := lut([]Point{{X: 10, Y: 0}, {X: 10, Y: 255}})
+	assert.Error(t, err)
+}
+
+func TestCurvesImage_ChannelBeforeMaster(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 100, G: 100, B: 100, A: 200})
+
+	// The red curve inverts red and the master curve then inverts all
+	// channels, so red ends up where it started.
+	invert := []Point{{X: 0, Y: 255}, {X: 255, Y: 0}}
+	params := CurvesParams{RGB: invert, Red: invert}
+	out, err := params.CurvesImage(img)
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 100, G: 155, B: 155, A: 200}, out.(*image.NRGBA).NRGBAAt(0, 0))
+}
@@ -0,0 +1,140 @@
+package denoise
+
+import (
+	"image"
+	"math"
+	"online-photo-editor/internal/lib/parallel"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// cutoff is the exponent beyond which a patch no longer contributes.
+	cutoff = 10
+	// lutEntries is the resolution of the weight table.
+	lutEntries = 1024
+)
+
+type DenoiseParams struct {
+	Strength     float64 `json:"strength" validate:"omitempty,min=1,max=100"`
+	PatchRadius  int     `json:"patch_radius" validate:"omitempty,min=1,max=3"`
+	SearchRadius int     `json:"search_radius" validate:"omitempty,min=1,max=7"`
+}
+
+// DenoiseImage removes noise with non-local means: every pixel becomes the
+// weighted average of the pixels within SearchRadius (default 5) whose
+// surrounding patches of PatchRadius (default 1) look alike. Strength
+// (default 10) should be close to the standard deviation of the noise;
+// higher values smooth more. Alpha is kept.
+func (params *DenoiseParams) DenoiseImage(img image.Image) (image.Image, error) {
+	strength, patch, search := 10.0, 1, 5
+	if params.Strength != 0 {
+		strength = params.Strength
+	}
+	if params.PatchRadius != 0 {
+		patch = params.PatchRadius
+	}
+	if params.SearchRadius != 0 {
+		search = params.SearchRadius
+	}
+
+	src := imaging.Clone(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	n := w * h
+
+	var planes [3][]float32
+	for c := range planes {
+		planes[c] = make([]float32, n)
+		for i := range planes[c] {
+			planes[c][i] = float32(src.Pix[i*4+c])
+		}
+	}
+
+	// weight maps the normalized patch distance to exp(-t) for t in
+	// [0, cutoff).
+	var weight [lutEntries]float32
+	for i := range weight {
+		weight[i] = float32(math.Exp(-float64(i) * cutoff / lutEntries))
+	}
+
+	// The pixel itself always takes part with full weight.
+	var acc [3][]float32
+	for c := range acc {
+		acc[c] = append([]float32(nil), planes[c]...)
+	}
+	total := make([]float32, n)
+	for i := range total {
+		total[i] = 1
+	}
+
+	diff := make([]float32, n)
+	integral := make([]float64, (w+1)*(h+1))
+	noise := 2 * strength * strength
+	scale := lutEntries / (cutoff * strength * strength)
+
+	for dy := -search; dy <= search; dy++ {
+		for dx := -search; dx <= search; dx++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+
+			// diff holds the squared color distance between every pixel and
+			// the one at the offset, averaged over the channels.
+			parallel.Rows(h, func(y int) {
+				oy := min(max(y+dy, 0), h-1)
+				for x := 0; x < w; x++ {
+					ox := min(max(x+dx, 0), w-1)
+					i, j := y*w+x, oy*w+ox
+					var d float32
+					for c := range planes {
+						v := planes[c][i] - planes[c][j]
+						d += v * v
+					}
+					diff[i] = d / 3
+				}
+			})
+
+			for y := 0; y < h; y++ {
+				row := 0.0
+				for x := 0; x < w; x++ {
+					row += float64(diff[y*w+x])
+					integral[(y+1)*(w+1)+x+1] = integral[y*(w+1)+x+1] + row
+				}
+			}
+
+			parallel.Rows(h, func(y int) {
+				y0, y1 := max(y-patch, 0), min(y+patch+1, h)
+				oy := min(max(y+dy, 0), h-1)
+				for x := 0; x < w; x++ {
+					x0, x1 := max(x-patch, 0), min(x+patch+1, w)
+					sum := integral[y1*(w+1)+x1] - integral[y0*(w+1)+x1] - integral[y1*(w+1)+x0] + integral[y0*(w+1)+x0]
+					dist := sum/float64((y1-y0)*(x1-x0)) - noise
+
+					k := 0
+					if dist > 0 {
+						k = int(dist * scale)
+						if k >= lutEntries {
+							continue
+						}
+					}
+
+					wt := weight[k]
+					i, j := y*w+x, oy*w+min(max(x+dx, 0), w-1)
+					for c := range acc {
+						acc[c][i] += wt * planes[c][j]
+					}
+					total[i] += wt
+				}
+			})
+		}
+	}
+
+	dst := imaging.Clone(src)
+	for i := 0; i < n; i++ {
+		for c := range acc {
+			dst.Pix[i*4+c] = uint8(math.Round(float64(acc[c][i] / total[i])))
+		}
+	}
+
+	return dst, nil
+}
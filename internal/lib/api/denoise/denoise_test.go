@@ -0,0 +1,80 @@
+package denoise_test
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"math/rand"
+	"online-photo-editor/internal/lib/api/denoise"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// noisyStep is a dark left half and a bright right half with gaussian noise
+// of the given deviation.
+func noisyStep(w, h int, sigma float64) *image.NRGBA {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			base := 60.0
+			if x >= w/2 {
+				base = 190
+			}
+			v := uint8(min(max(math.Round(base+rng.NormFloat64()*sigma), 0), 255))
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+// deviation returns the mean and standard deviation of the red channel in
+// columns [x0, x1).
+func deviation(img *image.NRGBA, x0, x1 int) (float64, float64) {
+	var sum, sq, n float64
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		for x := x0; x < x1; x++ {
+			v := float64(img.NRGBAAt(x, y).R)
+			sum += v
+			sq += v * v
+			n++
+		}
+	}
+	mean := sum / n
+
+	return mean, math.Sqrt(sq/n - mean*mean)
+}
+
+func TestDenoiseImage_SmoothsNoiseAndKeepsEdges(t *testing.T) {
+	src := noisyStep(40, 30, 10)
+
+	out, err := (&denoise.DenoiseParams{Strength: 10}).DenoiseImage(src)
+	assert.NoError(t, err)
+	dst := out.(*image.NRGBA)
+
+	for _, half := range [][2]int{{0, 20}, {20, 40}} {
+		srcMean, srcDev := deviation(src, half[0], half[1])
+		dstMean, dstDev := deviation(dst, half[0], half[1])
+		assert.Less(t, dstDev, srcDev/2, "columns %v", half)
+		assert.InDelta(t, srcMean, dstMean, 3, "columns %v", half)
+	}
+
+	// The pixels next to the edge must not be averaged with the other side.
+	for y := 0; y < 30; y++ {
+		assert.Less(t, dst.NRGBAAt(19, y).R, uint8(100))
+		assert.Greater(t, dst.NRGBAAt(20, y).R, uint8(150))
+	}
+}
+
+func TestDenoiseImage_KeepsFlatImage(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 12, 12))
+	for i := range src.Pix {
+		src.Pix[i] = 128
+	}
+
+	out, err := (&denoise.DenoiseParams{}).DenoiseImage(src)
+	assert.NoError(t, err)
+	assert.Equal(t, src.Pix, out.(*image.NRGBA).Pix)
+}
@@ -0,0 +1,301 @@
+package drawing
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/geometry"
+	"online-photo-editor/internal/lib/mask"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	ShapeLine        = "line"
+	ShapeArrow       = "arrow"
+	ShapeRect        = "rect"
+	ShapeRoundedRect = "rounded_rect"
+	ShapeEllipse     = "ellipse"
+	ShapePolygon     = "polygon"
+	ShapePolyline    = "polyline"
+	ShapePath        = "path"
+)
+
+const (
+	defaultStroke      = "#000000"
+	defaultStrokeWidth = 2
+)
+
+type DrawParams struct {
+	Shapes []Shape `json:"shapes" validate:"required,min=1,max=256,dive"`
+}
+
+type Shape struct {
+	Type        string           `json:"type" validate:"required,oneof=line arrow rect rounded_rect ellipse polygon polyline path"`
+	Points      []geometry.Point `json:"points" validate:"max=1024"`
+	Rect        *geometry.Rect   `json:"rect"`
+	Radius      float64          `json:"radius" validate:"min=0"`
+	Ellipse     *mask.Ellipse    `json:"ellipse"`
+	Path        string           `json:"path" validate:"max=10000"`
+	Stroke      string           `json:"stroke" validate:"omitempty,max=20"`
+	StrokeWidth float64          `json:"stroke_width" validate:"min=0,max=200"`
+	Fill        string           `json:"fill" validate:"omitempty,max=20"`
+	Opacity     *float64         `json:"opacity" validate:"omitempty,min=0,max=100"`
+	HeadSize    float64          `json:"head_size" validate:"min=0,max=500"`
+	Antialias   *bool            `json:"antialias"`
+}
+
+// DrawImage renders the shapes over the image in order. A shape without
+// stroke and fill is outlined in black. StrokeWidth defaults to 2 pixels,
+// Opacity to 100 percent and anti-aliasing is on unless Antialias is false.
+func (params *DrawParams) DrawImage(img image.Image) (image.Image, error) {
+	const op = "api.drawing.DrawImage"
+
+	dst := imaging.Clone(img)
+	for i, shape := range params.Shapes {
+		if err := shape.draw(dst); err != nil {
+			return nil, fmt.Errorf("%s: shape %d: %w", op, i, err)
+		}
+	}
+
+	return dst, nil
+}
+
+func (s *Shape) draw(dst *image.NRGBA) error {
+	outline, closed, err := s.outline()
+	if err != nil {
+		return err
+	}
+
+	opacity := 1.0
+	if s.Opacity != nil {
+		opacity = *s.Opacity / 100
+	}
+	antialias := s.Antialias == nil || *s.Antialias
+
+	stroke, fill := s.Stroke, s.Fill
+	if stroke == "" && fill == "" {
+		stroke = defaultStroke
+	}
+
+	lines := outline.flatten()
+	for _, line := range append(lines, s.Points) {
+		if !geometry.InRange(line) {
+			return fmt.Errorf("coordinates must be within ±%d", int(geometry.MaxCoordinate))
+		}
+	}
+
+	if fill != "" {
+		if !closed {
+			return fmt.Errorf("%s cannot be filled", s.Type)
+		}
+		c, err := colors.Parse(fill)
+		if err != nil {
+			return err
+		}
+		composite(dst, rasterize(dst.Rect, lines, false, antialias), c, opacity)
+	}
+
+	if stroke != "" {
+		c, err := colors.Parse(stroke)
+		if err != nil {
+			return err
+		}
+		width := s.StrokeWidth
+		if width == 0 {
+			width = defaultStrokeWidth
+		}
+
+		pieces := strokePolygons(lines, width/2)
+		if s.Type == ShapeArrow {
+			pieces = append(pieces, s.arrowHead(width))
+		}
+		composite(dst, rasterize(dst.Rect, pieces, true, antialias), c, opacity)
+	}
+
+	return nil
+}
+
+// outline returns the geometry of the shape and whether it is closed.
+func (s *Shape) outline() (path, bool, error) {
+	switch s.Type {
+	case ShapeLine, ShapeArrow:
+		if len(s.Points) != 2 {
+			return nil, false, fmt.Errorf("%s needs exactly 2 points", s.Type)
+		}
+		if s.Type == ShapeArrow {
+			return polyline([]geometry.Point{s.Points[0], s.arrowBase()}, false), false, nil
+		}
+		return polyline(s.Points, false), false, nil
+	case ShapePolyline:
+		if len(s.Points) < 2 {
+			return nil, false, fmt.Errorf("%s needs at least 2 points", s.Type)
+		}
+		return polyline(s.Points, false), false, nil
+	case ShapePolygon:
+		if len(s.Points) < 3 {
+			return nil, false, fmt.Errorf("%s needs at least 3 points", s.Type)
+		}
+		return polyline(s.Points, true), true, nil
+	case ShapeRect, ShapeRoundedRect:
+		if s.Rect == nil {
+			return nil, false, fmt.Errorf("%s needs a rect", s.Type)
+		}
+		r := s.Rect
+		radius := 0.0
+		if s.Type == ShapeRoundedRect {
+			radius = s.Radius
+		}
+		return roundedRect(float64(r.X), float64(r.Y), float64(r.Width), float64(r.Height), radius), true, nil
+	case ShapeEllipse:
+		if s.Ellipse == nil {
+			return nil, false, fmt.Errorf("%s needs an ellipse", s.Type)
+		}
+		e := s.Ellipse
+		return ellipse(e.X, e.Y, e.RadiusX, e.RadiusY), true, nil
+	case ShapePath:
+		p, err := parsePath(s.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		return p, true, nil
+	default:
+		return nil, false, fmt.Errorf("unknown shape type %q", s.Type)
+	}
+}
+
+// headSize returns the length of the arrow head, which is also its width.
+func (s *Shape) headSize(width float64) float64 {
+	if s.HeadSize > 0 {
+		return s.HeadSize
+	}
+	if width == 0 {
+		width = defaultStrokeWidth
+	}
+
+	return max(4*width, 10)
+}
+
+// arrowBase returns where the shaft of an arrow meets its head. An arrow
+// shorter than its head has no shaft.
+func (s *Shape) arrowBase() geometry.Point {
+	from, to := s.Points[0], s.Points[1]
+	length := math.Hypot(to.X-from.X, to.Y-from.Y)
+	size := s.headSize(s.StrokeWidth)
+	if length <= size {
+		return from
+	}
+
+	t := (length - size) / length
+	return geometry.Point{X: from.X + (to.X-from.X)*t, Y: from.Y + (to.Y-from.Y)*t}
+}
+
+// arrowHead returns the triangle at the end of an arrow.
+func (s *Shape) arrowHead(width float64) []geometry.Point {
+	from, to := s.Points[0], s.Points[1]
+	length := math.Hypot(to.X-from.X, to.Y-from.Y)
+	if length == 0 {
+		return nil
+	}
+
+	size := s.headSize(width)
+	dx, dy := (to.X-from.X)/length, (to.Y-from.Y)/length
+	bx, by := to.X-dx*size, to.Y-dy*size
+	nx, ny := -dy*size/2, dx*size/2
+
+	return []geometry.Point{to, {X: bx + nx, Y: by + ny}, {X: bx - nx, Y: by - ny}}
+}
+
+// strokePolygons covers every polyline with a quad per segment and a disk at
+// every vertex, which gives round joins and caps.
+func strokePolygons(lines [][]geometry.Point, halfWidth float64) [][]geometry.Point {
+	var pieces [][]geometry.Point
+	for _, line := range lines {
+		for i, a := range line {
+			pieces = append(pieces, disk(a, halfWidth))
+			if i == 0 {
+				continue
+			}
+
+			b := line[i-1]
+			length := math.Hypot(a.X-b.X, a.Y-b.Y)
+			if length == 0 {
+				continue
+			}
+			nx, ny := -(a.Y-b.Y)/length*halfWidth, (a.X-b.X)/length*halfWidth
+			pieces = append(pieces, []geometry.Point{
+				{X: b.X + nx, Y: b.Y + ny},
+				{X: a.X + nx, Y: a.Y + ny},
+				{X: a.X - nx, Y: a.Y - ny},
+				{X: b.X - nx, Y: b.Y - ny},
+			})
+		}
+	}
+
+	return pieces
+}
+
+// disk approximates a circle with a regular polygon fine enough that the
+// error stays below a tenth of a pixel.
+func disk(c geometry.Point, r float64) []geometry.Point {
+	n := 8
+	if r > 0 {
+		n = min(max(int(math.Ceil(math.Pi/math.Acos(1-0.1/max(r, 0.1)))), 8), 128)
+	}
+
+	pts := make([]geometry.Point, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = geometry.Point{X: c.X + r*math.Cos(a), Y: c.Y + r*math.Sin(a)}
+	}
+
+	return pts
+}
+
+// rasterize renders the polygons into a mask over the part of bounds they
+// cover, see mask.Polygons. Without antialias every pixel is either set or
+// not.
+func rasterize(bounds image.Rectangle, polygons [][]geometry.Point, orient, antialias bool) *image.Alpha {
+	m := mask.Polygons(bounds, polygons, orient)
+
+	if !antialias {
+		for i, a := range m.Pix {
+			if a >= 0x80 {
+				m.Pix[i] = 0xff
+			} else {
+				m.Pix[i] = 0
+			}
+		}
+	}
+
+	return m
+}
+
+// composite paints c over dst where m is set.
+func composite(dst *image.NRGBA, m *image.Alpha, c color.NRGBA, opacity float64) {
+	alpha := float64(c.A) / 0xff * opacity
+	src := [3]float64{float64(c.R), float64(c.G), float64(c.B)}
+
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		for x := m.Rect.Min.X; x < m.Rect.Max.X; x++ {
+			cov := m.Pix[m.PixOffset(x, y)]
+			if cov == 0 {
+				continue
+			}
+
+			sa := float64(cov) / 0xff * alpha
+			i := dst.PixOffset(x, y)
+			da := float64(dst.Pix[i+3]) / 0xff
+			oa := sa + da*(1-sa)
+			if oa == 0 {
+				continue
+			}
+			for ch := 0; ch < 3; ch++ {
+				dst.Pix[i+ch] = colors.Clamp8((src[ch]*sa + float64(dst.Pix[i+ch])*da*(1-sa)) / oa)
+			}
+			dst.Pix[i+3] = colors.Clamp8(oa * 0xff)
+		}
+	}
+}
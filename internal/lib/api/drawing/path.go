@@ -0,0 +1,321 @@
+package drawing
+
+import (
+	"fmt"
+	"math"
+	"online-photo-editor/internal/lib/geometry"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// kappa places the control points of the four cubic Béziers that
+// approximate a quarter of an ellipse each.
+const kappa = 0.5522847498
+
+// segment is a line ('L'), quadratic ('Q') or cubic ('C') Bézier segment.
+// The last used point is the end point.
+type segment struct {
+	op  byte
+	pts [3]geometry.Point
+}
+
+type subpath struct {
+	start  geometry.Point
+	segs   []segment
+	closed bool
+}
+
+type path []subpath
+
+func (p *path) moveTo(pt geometry.Point) {
+	*p = append(*p, subpath{start: pt})
+}
+
+func (p *path) lineTo(pt geometry.Point) {
+	s := &(*p)[len(*p)-1]
+	s.segs = append(s.segs, segment{op: 'L', pts: [3]geometry.Point{pt}})
+}
+
+func (p *path) quadTo(c, pt geometry.Point) {
+	s := &(*p)[len(*p)-1]
+	s.segs = append(s.segs, segment{op: 'Q', pts: [3]geometry.Point{c, pt}})
+}
+
+func (p *path) cubeTo(c1, c2, pt geometry.Point) {
+	s := &(*p)[len(*p)-1]
+	s.segs = append(s.segs, segment{op: 'C', pts: [3]geometry.Point{c1, c2, pt}})
+}
+
+func (p *path) close() {
+	(*p)[len(*p)-1].closed = true
+}
+
+// polyline returns a path through points, closed if requested.
+func polyline(points []geometry.Point, closed bool) path {
+	var p path
+	p.moveTo(points[0])
+	for _, pt := range points[1:] {
+		p.lineTo(pt)
+	}
+	if closed {
+		p.close()
+	}
+
+	return p
+}
+
+// roundedRect returns the outline of a rectangle whose corners are quarter
+// ellipses of the given radius, clamped to half of the shorter side.
+func roundedRect(x, y, w, h, r float64) path {
+	r = min(r, w/2, h/2)
+	k := r * (1 - kappa)
+
+	var p path
+	p.moveTo(geometry.Point{X: x + r, Y: y})
+	p.lineTo(geometry.Point{X: x + w - r, Y: y})
+	p.cubeTo(geometry.Point{X: x + w - k, Y: y}, geometry.Point{X: x + w, Y: y + k}, geometry.Point{X: x + w, Y: y + r})
+	p.lineTo(geometry.Point{X: x + w, Y: y + h - r})
+	p.cubeTo(geometry.Point{X: x + w, Y: y + h - k}, geometry.Point{X: x + w - k, Y: y + h}, geometry.Point{X: x + w - r, Y: y + h})
+	p.lineTo(geometry.Point{X: x + r, Y: y + h})
+	p.cubeTo(geometry.Point{X: x + k, Y: y + h}, geometry.Point{X: x, Y: y + h - k}, geometry.Point{X: x, Y: y + h - r})
+	p.lineTo(geometry.Point{X: x, Y: y + r})
+	p.cubeTo(geometry.Point{X: x, Y: y + k}, geometry.Point{X: x + k, Y: y}, geometry.Point{X: x + r, Y: y})
+	p.close()
+
+	return p
+}
+
+// ellipse returns the outline of an axis-aligned ellipse.
+func ellipse(cx, cy, rx, ry float64) path {
+	kx, ky := kappa*rx, kappa*ry
+
+	var p path
+	p.moveTo(geometry.Point{X: cx + rx, Y: cy})
+	p.cubeTo(geometry.Point{X: cx + rx, Y: cy + ky}, geometry.Point{X: cx + kx, Y: cy + ry}, geometry.Point{X: cx, Y: cy + ry})
+	p.cubeTo(geometry.Point{X: cx - kx, Y: cy + ry}, geometry.Point{X: cx - rx, Y: cy + ky}, geometry.Point{X: cx - rx, Y: cy})
+	p.cubeTo(geometry.Point{X: cx - rx, Y: cy - ky}, geometry.Point{X: cx - kx, Y: cy - ry}, geometry.Point{X: cx, Y: cy - ry})
+	p.cubeTo(geometry.Point{X: cx + kx, Y: cy - ry}, geometry.Point{X: cx + rx, Y: cy - ky}, geometry.Point{X: cx + rx, Y: cy})
+	p.close()
+
+	return p
+}
+
+// parsePath reads SVG path data with the M, L, H, V, Q, C and Z commands in
+// absolute and relative form.
+func parsePath(data string) (path, error) {
+	const op = "api.drawing.parsePath"
+
+	tokens := tokenize(data)
+	var p path
+	var cmd byte
+	var cur geometry.Point
+
+	for i := 0; i < len(tokens); {
+		if t := tokens[i]; len(t) == 1 && unicode.IsLetter(rune(t[0])) {
+			cmd = t[0]
+			i++
+		} else if cmd == 0 {
+			return nil, fmt.Errorf("%s: path must start with a command, got %q", op, t)
+		}
+
+		var args []float64
+		take := func(n int) error {
+			if i+n > len(tokens) {
+				return fmt.Errorf("%s: command %c needs %d numbers", op, cmd, n)
+			}
+			args = args[:0]
+			for _, t := range tokens[i : i+n] {
+				v, err := strconv.ParseFloat(t, 64)
+				if err != nil {
+					return fmt.Errorf("%s: invalid number %q", op, t)
+				}
+				args = append(args, v)
+			}
+			i += n
+			return nil
+		}
+		rel := unicode.IsLower(rune(cmd))
+		abs := func(x, y float64) geometry.Point {
+			if rel {
+				return geometry.Point{X: cur.X + x, Y: cur.Y + y}
+			}
+			return geometry.Point{X: x, Y: y}
+		}
+		if len(p) == 0 && cmd != 'M' && cmd != 'm' {
+			return nil, fmt.Errorf("%s: path must start with M", op)
+		}
+
+		switch unicode.ToUpper(rune(cmd)) {
+		case 'M':
+			if err := take(2); err != nil {
+				return nil, err
+			}
+			cur = abs(args[0], args[1])
+			p.moveTo(cur)
+			// Further coordinate pairs are implicit line commands.
+			if rel {
+				cmd = 'l'
+			} else {
+				cmd = 'L'
+			}
+		case 'L':
+			if err := take(2); err != nil {
+				return nil, err
+			}
+			cur = abs(args[0], args[1])
+			p.lineTo(cur)
+		case 'H':
+			if err := take(1); err != nil {
+				return nil, err
+			}
+			if rel {
+				cur.X += args[0]
+			} else {
+				cur.X = args[0]
+			}
+			p.lineTo(cur)
+		case 'V':
+			if err := take(1); err != nil {
+				return nil, err
+			}
+			if rel {
+				cur.Y += args[0]
+			} else {
+				cur.Y = args[0]
+			}
+			p.lineTo(cur)
+		case 'Q':
+			if err := take(4); err != nil {
+				return nil, err
+			}
+			c, end := abs(args[0], args[1]), abs(args[2], args[3])
+			p.quadTo(c, end)
+			cur = end
+		case 'C':
+			if err := take(6); err != nil {
+				return nil, err
+			}
+			c1, c2, end := abs(args[0], args[1]), abs(args[2], args[3]), abs(args[4], args[5])
+			p.cubeTo(c1, c2, end)
+			cur = end
+		case 'Z':
+			p.close()
+			cur = p[len(p)-1].start
+			// A command after Z without M continues from the start point.
+			p.moveTo(cur)
+			cmd = 0
+		default:
+			return nil, fmt.Errorf("%s: unsupported command %c", op, cmd)
+		}
+	}
+
+	// Drop the empty subpaths left by Z.
+	kept := p[:0]
+	for _, s := range p {
+		if len(s.segs) > 0 {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == 0 {
+		return nil, fmt.Errorf("%s: path is empty", op)
+	}
+
+	return kept, nil
+}
+
+// tokenize splits SVG path data into command letters and numbers.
+func tokenize(data string) []string {
+	var tokens []string
+	var b strings.Builder
+	flush := func() {
+		if b.Len() > 0 {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		case (c == 'e' || c == 'E') && b.Len() > 0:
+			b.WriteByte(c)
+		case unicode.IsLetter(rune(c)):
+			flush()
+			tokens = append(tokens, string(c))
+		case c == '-' || c == '+':
+			// A sign starts a new number unless it belongs to an exponent.
+			if s := b.String(); s != "" && !strings.HasSuffix(s, "e") && !strings.HasSuffix(s, "E") {
+				flush()
+			}
+			b.WriteByte(c)
+		case c == '.':
+			// A second dot starts a new number, as in "0.5.5".
+			if strings.Contains(b.String(), ".") {
+				flush()
+			}
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// flatten approximates every subpath with a polyline. Closed subpaths end
+// with their start point.
+func (p path) flatten() [][]geometry.Point {
+	var lines [][]geometry.Point
+	for _, s := range p {
+		pts := []geometry.Point{s.start}
+		cur := s.start
+		for _, seg := range s.segs {
+			switch seg.op {
+			case 'L':
+				pts = append(pts, seg.pts[0])
+			case 'Q':
+				n := steps(cur, seg.pts[0], seg.pts[1])
+				for i := 1; i <= n; i++ {
+					t := float64(i) / float64(n)
+					u := 1 - t
+					pts = append(pts, geometry.Point{
+						X: u*u*cur.X + 2*u*t*seg.pts[0].X + t*t*seg.pts[1].X,
+						Y: u*u*cur.Y + 2*u*t*seg.pts[0].Y + t*t*seg.pts[1].Y,
+					})
+				}
+			case 'C':
+				n := steps(cur, seg.pts[0], seg.pts[1], seg.pts[2])
+				for i := 1; i <= n; i++ {
+					t := float64(i) / float64(n)
+					u := 1 - t
+					pts = append(pts, geometry.Point{
+						X: u*u*u*cur.X + 3*u*u*t*seg.pts[0].X + 3*u*t*t*seg.pts[1].X + t*t*t*seg.pts[2].X,
+						Y: u*u*u*cur.Y + 3*u*u*t*seg.pts[0].Y + 3*u*t*t*seg.pts[1].Y + t*t*t*seg.pts[2].Y,
+					})
+				}
+			}
+			cur = pts[len(pts)-1]
+		}
+		if s.closed {
+			pts = append(pts, s.start)
+		}
+		lines = append(lines, pts)
+	}
+
+	return lines
+}
+
+// steps returns how many line segments approximate a curve with the given
+// control polygon closely enough: about one per four pixels of its length.
+func steps(pts ...geometry.Point) int {
+	length := 0.0
+	for i := 1; i < len(pts); i++ {
+		length += math.Hypot(pts[i].X-pts[i-1].X, pts[i].Y-pts[i-1].Y)
+	}
+
+	return min(max(int(length/4), 4), 256)
+}
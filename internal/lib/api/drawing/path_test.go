@@ -0,0 +1,94 @@
+package drawing
+
+import (
+	"online-photo-editor/internal/lib/geometry"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{"separators", "M10,20 L 30\t40\n", []string{"M", "10", "20", "L", "30", "40"}},
+		{"signs split numbers", "l5-5-5+5", []string{"l", "5", "-5", "-5", "+5"}},
+		{"exponents", "M1e-5-2e3 1E+2", []string{"M", "1e-5", "-2e3", "1E+2"}},
+		{"second dot starts a number", "M0.5.5", []string{"M", "0.5", ".5"}},
+		{"commands without spaces", "M0 0L1 1Z", []string{"M", "0", "0", "L", "1", "1", "Z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tokenize(tt.data))
+		})
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	pt := func(x, y float64) geometry.Point { return geometry.Point{X: x, Y: y} }
+	line := func(x, y float64) segment { return segment{op: 'L', pts: [3]geometry.Point{pt(x, y)}} }
+
+	tests := []struct {
+		name string
+		data string
+		want path
+	}{
+		{
+			name: "implicit line after move",
+			data: "M 0 0 10 0 10 10",
+			want: path{{start: pt(0, 0), segs: []segment{line(10, 0), line(10, 10)}}},
+		},
+		{
+			name: "relative commands",
+			data: "m 5 5 l 10 0 v 10 h -10 z",
+			want: path{{start: pt(5, 5), segs: []segment{line(15, 5), line(15, 15), line(5, 15)}, closed: true}},
+		},
+		{
+			name: "curves",
+			data: "M0 0 Q 5 10 10 0 c 0 5 5 5 5 0",
+			want: path{{start: pt(0, 0), segs: []segment{
+				{op: 'Q', pts: [3]geometry.Point{pt(5, 10), pt(10, 0)}},
+				{op: 'C', pts: [3]geometry.Point{pt(10, 5), pt(15, 5), pt(15, 0)}},
+			}}},
+		},
+		{
+			name: "commands after close continue from the start",
+			data: "M 1 1 L 5 1 L 5 5 Z l 0 10 l 10 0",
+			want: path{
+				{start: pt(1, 1), segs: []segment{line(5, 1), line(5, 5)}, closed: true},
+				{start: pt(1, 1), segs: []segment{line(1, 11), line(11, 11)}},
+			},
+		},
+		{
+			name: "exponents",
+			data: "M1e1-2e0L0.5.5",
+			want: path{{start: pt(10, -2), segs: []segment{line(0.5, 0.5)}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parsePath(tt.data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParsePath_Errors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"L 1 1",
+		"10 10",
+		"M 1",
+		"M 1 1 L 2 x",
+		"M 1 1 A 1 1 0 0 0 2 2",
+		"M 1 1",
+	} {
+		_, err := parsePath(data)
+		assert.Error(t, err, data)
+	}
+}
@@ -0,0 +1,64 @@
+package edgedetect
+
+import (
+	"image"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/convolution"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	prewittOperator   = "prewitt"
+	laplacianOperator = "laplacian"
+)
+
+type EdgeDetectParams struct {
+	Operator string `json:"operator" validate:"omitempty,oneof=sobel prewitt laplacian"`
+}
+
+// EdgeDetectImage returns a grayscale edge map of the luma of the image,
+// brighter where the edges are stronger. Sobel (the default) and Prewitt use
+// the gradient magnitude, Laplacian the absolute second derivative.
+func (params *EdgeDetectParams) EdgeDetectImage(img image.Image) (image.Image, error) {
+	planes := convolution.Split(imaging.Clone(img))
+
+	luma := convolution.NewPlane(planes[0].W, planes[0].H)
+	for i := range luma.Pix {
+		luma.Pix[i] = colors.Luma(planes[0].Pix[i], planes[1].Pix[i], planes[2].Pix[i])
+	}
+
+	var edges *convolution.Plane
+	switch params.Operator {
+	case laplacianOperator:
+		edges = luma.Convolve([][]float64{
+			{0, 1, 0},
+			{1, -4, 1},
+			{0, 1, 0},
+		}, convolution.Extend).Map(math.Abs)
+	default:
+		side := 2.0
+		if params.Operator == prewittOperator {
+			side = 1
+		}
+
+		gx := luma.Convolve([][]float64{
+			{-1, 0, 1},
+			{-side, 0, side},
+			{-1, 0, 1},
+		}, convolution.Extend)
+		gy := luma.Convolve([][]float64{
+			{-1, -side, -1},
+			{0, 0, 0},
+			{1, side, 1},
+		}, convolution.Extend)
+
+		edges = gx
+		for i, v := range gx.Pix {
+			edges.Pix[i] = math.Hypot(v, gy.Pix[i])
+		}
+	}
+
+	return convolution.Merge([4]*convolution.Plane{edges, edges, edges, planes[3]}), nil
+}
@@ -0,0 +1,79 @@
+package edgedetect_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/edgedetect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// step returns an 8 x 3 image whose left half is gray lo and right half is
+// gray hi, a vertical edge between x = 3 and x = 4.
+func step(lo, hi uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 8; x++ {
+			v := lo
+			if x >= 4 {
+				v = hi
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+// middle returns the gray values of the middle row, checking that the
+// pixels are gray and opaque.
+func middle(t *testing.T, img image.Image) []uint8 {
+	t.Helper()
+
+	var values []uint8
+	for x := 0; x < img.Bounds().Dx(); x++ {
+		c := color.NRGBAModel.Convert(img.At(x, 1)).(color.NRGBA)
+		require.Equal(t, color.NRGBA{R: c.R, G: c.R, B: c.R, A: 255}, c, "pixel %d", x)
+		values = append(values, c.R)
+	}
+
+	return values
+}
+
+func TestEdgeDetectImage_VerticalEdge(t *testing.T) {
+	tests := []struct {
+		operator string
+		want     []uint8
+	}{
+		// The gradient across the edge is 50 per row, weighted 1, 2, 1.
+		{"", []uint8{0, 0, 0, 200, 200, 0, 0, 0}},
+		{"sobel", []uint8{0, 0, 0, 200, 200, 0, 0, 0}},
+		{"prewitt", []uint8{0, 0, 0, 150, 150, 0, 0, 0}},
+		{"laplacian", []uint8{0, 0, 0, 50, 50, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.operator, func(t *testing.T) {
+			params := edgedetect.EdgeDetectParams{Operator: tt.operator}
+			out, err := params.EdgeDetectImage(step(0, 50))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, middle(t, out))
+		})
+	}
+}
+
+func TestEdgeDetectImage_UsesLumaAndKeepsAlpha(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{A: 80})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 100, A: 80})
+
+	params := edgedetect.EdgeDetectParams{Operator: "laplacian"}
+	out, err := params.EdgeDetectImage(img)
+	require.NoError(t, err)
+
+	// The luma of the blue pixel is 11.4.
+	assert.Equal(t, color.NRGBA{R: 11, G: 11, B: 11, A: 80}, out.At(0, 0))
+	assert.Equal(t, color.NRGBA{R: 11, G: 11, B: 11, A: 80}, out.At(1, 0))
+}
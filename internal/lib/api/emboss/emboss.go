@@ -0,0 +1,30 @@
+package emboss
+
+import (
+	"image"
+	"online-photo-editor/internal/lib/api/convolve"
+)
+
+type EmbossParams struct {
+	Strength float64 `json:"strength" validate:"omitempty,min=0.1,max=10"`
+}
+
+// EmbossImage makes the image look raised from the surface, lit from the top
+// left. Strength defaults to 1.
+func (params *EmbossParams) EmbossImage(img image.Image) (image.Image, error) {
+	s := params.Strength
+	if s == 0 {
+		s = 1
+	}
+
+	convolveParams := convolve.ConvolveParams{
+		Kernel: [][]float64{
+			{-s, -s, 0},
+			{-s, 1, s},
+			{0, s, s},
+		},
+		Divisor: 1,
+	}
+
+	return convolveParams.ConvolveImage(img)
+}
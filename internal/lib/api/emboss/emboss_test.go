@@ -0,0 +1,68 @@
+package emboss_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/emboss"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// step returns an 8 x 3 image whose left half is gray lo and right half is
+// gray hi, a vertical edge between x = 3 and x = 4.
+func step(lo, hi uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 8; x++ {
+			v := lo
+			if x >= 4 {
+				v = hi
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+// middle returns the gray values of the middle row, checking that the
+// pixels are gray and opaque.
+func middle(t *testing.T, img image.Image) []uint8 {
+	t.Helper()
+
+	var values []uint8
+	for x := 0; x < img.Bounds().Dx(); x++ {
+		c := color.NRGBAModel.Convert(img.At(x, 1)).(color.NRGBA)
+		require.Equal(t, color.NRGBA{R: c.R, G: c.R, B: c.R, A: 255}, c, "pixel %d", x)
+		values = append(values, c.R)
+	}
+
+	return values
+}
+
+func TestEmbossImage_FlatAreasKeepTheirTone(t *testing.T) {
+	out, err := (&emboss.EmbossParams{Strength: 3}).EmbossImage(step(100, 100))
+	require.NoError(t, err)
+	assert.Equal(t, []uint8{100, 100, 100, 100, 100, 100, 100, 100}, middle(t, out))
+}
+
+func TestEmbossImage_VerticalEdge(t *testing.T) {
+	tests := []struct {
+		strength float64
+		want     []uint8
+	}{
+		// Only the pixels next to the edge change.
+		{0, []uint8{0, 0, 0, 100, 150, 50, 50, 50}},
+		{1, []uint8{0, 0, 0, 100, 150, 50, 50, 50}},
+		{2, []uint8{0, 0, 0, 200, 250, 50, 50, 50}},
+	}
+
+	for _, tt := range tests {
+		params := emboss.EmbossParams{Strength: tt.strength}
+		out, err := params.EmbossImage(step(0, 50))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, middle(t, out), "strength %v", tt.strength)
+	}
+}
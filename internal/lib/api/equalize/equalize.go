@@ -0,0 +1,35 @@
+package equalize
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/histogram"
+
+	"github.com/disintegration/imaging"
+)
+
+type EqualizeParams struct{}
+
+// EqualizeImage spreads the brightness of the image evenly over the full
+// range. Only the luma is equalized so colors are kept.
+func (params *EqualizeParams) EqualizeImage(img image.Image) (image.Image, error) {
+	src := imaging.Clone(img)
+
+	var hist histogram.Histogram
+	for i := 0; i < len(src.Pix); i += 4 {
+		if src.Pix[i+3] == 0 {
+			continue
+		}
+		y, _, _ := color.RGBToYCbCr(src.Pix[i], src.Pix[i+1], src.Pix[i+2])
+		hist[y]++
+	}
+
+	lut := hist.Equalize()
+
+	return imaging.AdjustFunc(src, func(c color.NRGBA) color.NRGBA {
+		y, cb, cr := color.RGBToYCbCr(c.R, c.G, c.B)
+		r, g, b := color.YCbCrToRGB(lut[y], cb, cr)
+
+		return color.NRGBA{R: r, G: g, B: b, A: c.A}
+	}), nil
+}
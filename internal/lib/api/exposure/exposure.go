@@ -0,0 +1,29 @@
+package exposure
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type ExposureParams struct {
+	Stops float64 `json:"stops" validate:"required,min=-10,max=10"`
+}
+
+// ExposureImage multiplies the light in the image by 2^stops. The scaling is
+// done in linear light so that one stop doubles or halves the exposure.
+func (params *ExposureParams) ExposureImage(img image.Image) (image.Image, error) {
+	gain := math.Exp2(params.Stops)
+
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = colors.ToSRGB(colors.ToLinear(uint8(v)) * gain)
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[c.R], G: lut[c.G], B: lut[c.B], A: c.A}
+	}), nil
+}
@@ -0,0 +1,94 @@
+package gradientmap
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type Stop struct {
+	Color    string   `json:"color" validate:"required,max=20"`
+	Position *float64 `json:"position" validate:"omitempty,min=0,max=100"`
+}
+
+type GradientMapParams struct {
+	Stops []Stop `json:"stops" validate:"required,min=2,max=16,dive"`
+}
+
+// gradient resolves the stops into colors and positions in [0, 255]. Stops
+// without positions are spread evenly; either all or none must have one.
+func (params *GradientMapParams) gradient() ([]color.NRGBA, []float64, error) {
+	const op = "api.gradientmap.gradient"
+
+	stopColors := make([]color.NRGBA, len(params.Stops))
+	positions := make([]float64, len(params.Stops))
+	withPosition := 0
+
+	for i, stop := range params.Stops {
+		c, err := colors.Parse(stop.Color)
+		if err != nil {
+			return nil, nil, err
+		}
+		stopColors[i] = c
+
+		positions[i] = float64(i) * 255 / float64(len(params.Stops)-1)
+		if stop.Position != nil {
+			positions[i] = *stop.Position * 255 / 100
+			withPosition++
+		}
+	}
+
+	if withPosition != 0 && withPosition != len(params.Stops) {
+		return nil, nil, fmt.Errorf("%s: either all or none of the stops must have a position", op)
+	}
+	for i := 1; i < len(positions); i++ {
+		if positions[i] < positions[i-1] {
+			return nil, nil, fmt.Errorf("%s: stop positions must be in ascending order", op)
+		}
+	}
+
+	return stopColors, positions, nil
+}
+
+// GradientMapImage maps the luma of every pixel onto a gradient through the
+// color stops, from the first stop for black to the last for white. Two
+// stops give a duotone.
+func (params *GradientMapParams) GradientMapImage(img image.Image) (image.Image, error) {
+	stopColors, positions, err := params.gradient()
+	if err != nil {
+		return nil, err
+	}
+
+	var lut [256]color.NRGBA
+	k := 0
+	for v := range lut {
+		x := float64(v)
+		for k < len(positions)-2 && positions[k+1] < x {
+			k++
+		}
+
+		a, b := stopColors[k], stopColors[k+1]
+		t := 0.0
+		if span := positions[k+1] - positions[k]; span > 0 {
+			t = min(max((x-positions[k])/span, 0), 1)
+		} else if x >= positions[k+1] {
+			t = 1
+		}
+
+		lut[v] = color.NRGBA{
+			R: colors.Clamp8(float64(a.R) + (float64(b.R)-float64(a.R))*t),
+			G: colors.Clamp8(float64(a.G) + (float64(b.G)-float64(a.G))*t),
+			B: colors.Clamp8(float64(a.B) + (float64(b.B)-float64(a.B))*t),
+		}
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		mapped := lut[colors.Clamp8(colors.Luma(float64(c.R), float64(c.G), float64(c.B)))]
+		mapped.A = c.A
+
+		return mapped
+	}), nil
+}
@@ -0,0 +1,118 @@
+package gradientmap_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/gradientmap"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func grays(values ...uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(values), 1))
+	for x, v := range values {
+		img.SetNRGBA(x, 0, color.NRGBA{R: v, G: v, B: v, A: 255})
+	}
+
+	return img
+}
+
+func row(img image.Image) []color.NRGBA {
+	var out []color.NRGBA
+	for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+		out = append(out, color.NRGBAModel.Convert(img.At(x, 0)).(color.NRGBA))
+	}
+
+	return out
+}
+
+func position(v float64) *float64 {
+	return &v
+}
+
+func TestGradientMapImage_Duotone(t *testing.T) {
+	params := gradientmap.GradientMapParams{Stops: []gradientmap.Stop{
+		{Color: "#000080"},
+		{Color: "#ff8000"},
+	}}
+
+	out, err := params.GradientMapImage(grays(0, 51, 255))
+	require.NoError(t, err)
+
+	assert.Equal(t, []color.NRGBA{
+		{R: 0, G: 0, B: 128, A: 255},
+		{R: 51, G: 26, B: 102, A: 255},
+		{R: 255, G: 128, B: 0, A: 255},
+	}, row(out))
+}
+
+func TestGradientMapImage_MapsLuma(t *testing.T) {
+	params := gradientmap.GradientMapParams{Stops: []gradientmap.Stop{
+		{Color: "black"},
+		{Color: "white"},
+	}}
+
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{G: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 255, A: 64})
+
+	out, err := params.GradientMapImage(img)
+	require.NoError(t, err)
+
+	// Black to white gives the rec601 luma; alpha is kept.
+	assert.Equal(t, []color.NRGBA{
+		{R: 150, G: 150, B: 150, A: 255},
+		{R: 29, G: 29, B: 29, A: 64},
+	}, row(out))
+}
+
+func TestGradientMapImage_Positions(t *testing.T) {
+	params := gradientmap.GradientMapParams{Stops: []gradientmap.Stop{
+		{Color: "#000000", Position: position(0)},
+		{Color: "#ff0000", Position: position(20)},
+		{Color: "#ff0000", Position: position(80)},
+		{Color: "#ffffff", Position: position(100)},
+	}}
+
+	out, err := params.GradientMapImage(grays(0, 51, 128, 204, 255))
+	require.NoError(t, err)
+
+	assert.Equal(t, []color.NRGBA{
+		{R: 0, G: 0, B: 0, A: 255},
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 255, G: 0, B: 0, A: 255},
+		{R: 255, G: 255, B: 255, A: 255},
+	}, row(out))
+}
+
+func TestGradientMapImage_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		stops []gradientmap.Stop
+		want  string
+	}{
+		{"some positions", []gradientmap.Stop{
+			{Color: "black", Position: position(0)},
+			{Color: "white"},
+		}, "either all or none"},
+		{"descending", []gradientmap.Stop{
+			{Color: "black", Position: position(60)},
+			{Color: "white", Position: position(40)},
+		}, "ascending order"},
+		{"bad color", []gradientmap.Stop{
+			{Color: "black"},
+			{Color: "nope"},
+		}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := gradientmap.GradientMapParams{Stops: tt.stops}
+			_, err := params.GradientMapImage(grays(0))
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package grayscale
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+// weights holds the channel weights of the predefined conversions.
+var weights = map[string][3]float64{
+	"rec601":  {0.299, 0.587, 0.114},
+	"rec709":  {0.2126, 0.7152, 0.0722},
+	"average": {1.0 / 3, 1.0 / 3, 1.0 / 3},
+}
+
+// Mixer sets the contribution of every channel in percent, like a channel
+// mixer in monochrome mode.
+type Mixer struct {
+	Red   float64 `json:"red" validate:"min=-200,max=200"`
+	Green float64 `json:"green" validate:"min=-200,max=200"`
+	Blue  float64 `json:"blue" validate:"min=-200,max=200"`
+}
+
+type GrayscaleParams struct {
+	Weights string `json:"weights" validate:"omitempty,oneof=rec601 rec709 average lightness"`
+	Mixer   *Mixer `json:"mixer"`
+}
+
+// GrayscaleImage converts the image to shades of gray. The weights default to
+// rec601; lightness averages the largest and smallest channel. A mixer
+// replaces the weights.
+func (params *GrayscaleParams) GrayscaleImage(img image.Image) (image.Image, error) {
+	w, ok := weights[params.Weights]
+	if !ok {
+		w = weights["rec601"]
+	}
+	if params.Mixer != nil {
+		w = [3]float64{params.Mixer.Red / 100, params.Mixer.Green / 100, params.Mixer.Blue / 100}
+	}
+
+	lightness := params.Weights == "lightness" && params.Mixer == nil
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+
+		var y uint8
+		if lightness {
+			y = colors.Clamp8((math.Max(r, math.Max(g, b)) + math.Min(r, math.Min(g, b))) / 2)
+		} else {
+			y = colors.Clamp8(w[0]*r + w[1]*g + w[2]*b)
+		}
+
+		return color.NRGBA{R: y, G: y, B: y, A: c.A}
+	}), nil
+}
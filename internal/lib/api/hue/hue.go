@@ -0,0 +1,24 @@
+package hue
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type HueParams struct {
+	Degrees float64 `json:"degrees" validate:"required,min=-360,max=360"`
+}
+
+// HueImage rotates the hue of every pixel by the given number of degrees,
+// keeping saturation and lightness.
+func (params *HueParams) HueImage(img image.Image) (image.Image, error) {
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		h, s, l := colors.RGBToHSL(float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+		r, g, b := colors.HSLToRGB(h+params.Degrees, s, l)
+
+		return color.NRGBA{R: colors.Clamp8(r * 255), G: colors.Clamp8(g * 255), B: colors.Clamp8(b * 255), A: c.A}
+	}), nil
+}
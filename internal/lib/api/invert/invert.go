@@ -0,0 +1,14 @@
+package invert
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+type InvertParams struct{}
+
+// InvertImage produces the negative of the image. Alpha is kept.
+func (params *InvertParams) InvertImage(img image.Image) (image.Image, error) {
+	return imaging.Invert(img), nil
+}
@@ -0,0 +1,95 @@
+package levels
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+// Levels maps the input range [InputBlack, InputWhite] onto the output range
+// [OutputBlack, OutputWhite]. Gamma above 1 brightens the midtones. The
+// white points default to 255 and gamma to 1.
+type Levels struct {
+	InputBlack  float64  `json:"input_black" validate:"min=0,max=255"`
+	InputWhite  *float64 `json:"input_white" validate:"omitempty,min=0,max=255"`
+	Gamma       float64  `json:"gamma" validate:"omitempty,min=0.1,max=10"`
+	OutputBlack float64  `json:"output_black" validate:"min=0,max=255"`
+	OutputWhite *float64 `json:"output_white" validate:"omitempty,min=0,max=255"`
+}
+
+// LevelsParams applies the embedded levels to all color channels. Levels
+// given for a single channel are applied before them.
+type LevelsParams struct {
+	Levels
+	Red   *Levels `json:"red"`
+	Green *Levels `json:"green"`
+	Blue  *Levels `json:"blue"`
+}
+
+func (l *Levels) lut() ([256]uint8, error) {
+	const op = "api.levels.lut"
+
+	var lut [256]uint8
+
+	inWhite, outWhite, gamma := 255.0, 255.0, 1.0
+	if l.InputWhite != nil {
+		inWhite = *l.InputWhite
+	}
+	if l.OutputWhite != nil {
+		outWhite = *l.OutputWhite
+	}
+	if l.Gamma != 0 {
+		gamma = l.Gamma
+	}
+
+	if inWhite <= l.InputBlack {
+		return lut, fmt.Errorf("%s: input_white must be greater than input_black", op)
+	}
+
+	for v := range lut {
+		x := math.Min(math.Max((float64(v)-l.InputBlack)/(inWhite-l.InputBlack), 0), 1)
+		x = math.Pow(x, 1/gamma)
+		lut[v] = colors.Clamp8(l.OutputBlack + x*(outWhite-l.OutputBlack))
+	}
+
+	return lut, nil
+}
+
+// LevelsImage remaps the tonal range of the image through per-channel and
+// master levels.
+func (params *LevelsParams) LevelsImage(img image.Image) (image.Image, error) {
+	master, err := params.Levels.lut()
+	if err != nil {
+		return nil, err
+	}
+
+	var luts [3][256]uint8
+	for i, channel := range []*Levels{params.Red, params.Green, params.Blue} {
+		lut := identity()
+		if channel != nil {
+			if lut, err = channel.lut(); err != nil {
+				return nil, err
+			}
+		}
+		for v := range lut {
+			luts[i][v] = master[lut[v]]
+		}
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: luts[0][c.R], G: luts[1][c.G], B: luts[2][c.B], A: c.A}
+	}), nil
+}
+
+func identity() [256]uint8 {
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = uint8(v)
+	}
+
+	return lut
+}
@@ -0,0 +1,334 @@
+package liquidresize
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/geometry"
+	"slices"
+)
+
+// protectEnergy is added to pixels inside the protected rectangle so seams
+// only pass through it when there is no other way.
+const protectEnergy = 1e6
+
+// maxSeamWork bounds the pixels visited over all seams of a request. Every
+// seam costs a full energy and cost pass over the image.
+const maxSeamWork = 1e9
+
+type LiquidResizeParams struct {
+	Width   int            `json:"width" validate:"min=0,max=8000"`
+	Height  int            `json:"height" validate:"min=0,max=8000"`
+	Protect *geometry.Rect `json:"protect"`
+}
+
+func (params *LiquidResizeParams) validate(img image.Image) error {
+	const op = "api.liquidresize.validate"
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	if params.Width == 0 && params.Height == 0 {
+		return fmt.Errorf("%s: width or height must be set", op)
+	}
+	if params.Width > 2*w || params.Height > 2*h {
+		return fmt.Errorf("%s: seam carving can at most double the image size", op)
+	}
+
+	work := 0
+	if params.Width > 0 {
+		work += w * h * abs(params.Width-w)
+		w = params.Width
+	}
+	if params.Height > 0 {
+		work += w * h * abs(params.Height-h)
+	}
+	if work > maxSeamWork {
+		return fmt.Errorf("%s: too many seams for the image size, resize it first", op)
+	}
+
+	return nil
+}
+
+// LiquidResizeImage changes the image size by removing or inserting
+// low-energy seams, width first. A zero dimension is left unchanged.
+func (params *LiquidResizeParams) LiquidResizeImage(img image.Image) (image.Image, error) {
+	if err := params.validate(img); err != nil {
+		return nil, err
+	}
+
+	var protect image.Rectangle
+	if params.Protect != nil {
+		protect = params.Protect.Rectangle(img.Bounds()).Sub(img.Bounds().Min)
+	}
+
+	g := newGrid(img, protect)
+
+	if params.Width > 0 {
+		g = g.resize(params.Width)
+	}
+	if params.Height > 0 {
+		g = g.transpose().resize(params.Height).transpose()
+	}
+
+	return g.image(), nil
+}
+
+type grid struct {
+	w, h    int
+	pix     []color.NRGBA
+	protect []bool
+	// origin holds the column each pixel had before any seam was removed.
+	origin []int
+	// energy is computed lazily and kept up to date as seams are removed.
+	energy []float64
+}
+
+func newGrid(img image.Image, protect image.Rectangle) *grid {
+	b := img.Bounds()
+	g := &grid{
+		w:       b.Dx(),
+		h:       b.Dy(),
+		pix:     make([]color.NRGBA, b.Dx()*b.Dy()),
+		protect: make([]bool, b.Dx()*b.Dy()),
+	}
+
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			g.pix[y*g.w+x] = color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			g.protect[y*g.w+x] = image.Pt(x, y).In(protect)
+		}
+	}
+
+	return g
+}
+
+func (g *grid) clone() *grid {
+	return &grid{
+		w:       g.w,
+		h:       g.h,
+		pix:     slices.Clone(g.pix),
+		protect: slices.Clone(g.protect),
+	}
+}
+
+func (g *grid) transpose() *grid {
+	t := &grid{
+		w:       g.h,
+		h:       g.w,
+		pix:     make([]color.NRGBA, len(g.pix)),
+		protect: make([]bool, len(g.protect)),
+	}
+
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			t.pix[x*t.w+y] = g.pix[y*g.w+x]
+			t.protect[x*t.w+y] = g.protect[y*g.w+x]
+		}
+	}
+
+	return t
+}
+
+func (g *grid) image() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, g.w, g.h))
+	for y := 0; y < g.h; y++ {
+		for x := 0; x < g.w; x++ {
+			img.SetNRGBA(x, y, g.pix[y*g.w+x])
+		}
+	}
+
+	return img
+}
+
+// resize changes the grid width to width by carving vertical seams.
+func (g *grid) resize(width int) *grid {
+	for g.w > width {
+		g.removeSeam(g.findSeam())
+	}
+
+	for g.w < width {
+		// Seams to duplicate are picked on a shrunk copy so that the same
+		// low-energy seam is not duplicated over and over. At most half of
+		// the width is inserted per round.
+		n := min(width-g.w, max(1, g.w/2))
+		g = g.insertSeams(n)
+	}
+
+	return g
+}
+
+func (g *grid) insertSeams(n int) *grid {
+	work := g.clone()
+	work.origin = make([]int, len(work.pix))
+	for i := range work.origin {
+		work.origin[i] = i % work.w
+	}
+
+	marked := make([][]int, g.h)
+	for i := 0; i < n; i++ {
+		seam := work.findSeam()
+		for y, x := range seam {
+			marked[y] = append(marked[y], work.origin[y*work.w+x])
+		}
+		work.removeSeam(seam)
+	}
+
+	out := &grid{
+		w:       g.w + n,
+		h:       g.h,
+		pix:     make([]color.NRGBA, 0, (g.w+n)*g.h),
+		protect: make([]bool, 0, (g.w+n)*g.h),
+	}
+
+	for y := 0; y < g.h; y++ {
+		slices.Sort(marked[y])
+		next := 0
+		for x := 0; x < g.w; x++ {
+			i := y*g.w + x
+			out.pix = append(out.pix, g.pix[i])
+			out.protect = append(out.protect, g.protect[i])
+
+			for next < len(marked[y]) && marked[y][next] == x {
+				right := g.pix[y*g.w+min(x+1, g.w-1)]
+				out.pix = append(out.pix, average(g.pix[i], right))
+				out.protect = append(out.protect, g.protect[i])
+				next++
+			}
+		}
+	}
+
+	return out
+}
+
+// findSeam returns the x coordinate of the lowest-energy vertical seam for
+// every row.
+func (g *grid) findSeam() []int {
+	if g.energy == nil {
+		g.energy = make([]float64, len(g.pix))
+		for y := 0; y < g.h; y++ {
+			for x := 0; x < g.w; x++ {
+				g.energy[y*g.w+x] = g.energyAt(x, y)
+			}
+		}
+	}
+	energy := g.energy
+	cost := make([]float64, len(energy))
+	copy(cost[:g.w], energy[:g.w])
+
+	for y := 1; y < g.h; y++ {
+		prev := cost[(y-1)*g.w : y*g.w]
+		row := cost[y*g.w : (y+1)*g.w]
+		for x := range row {
+			best := prev[x]
+			if x > 0 && prev[x-1] < best {
+				best = prev[x-1]
+			}
+			if x < g.w-1 && prev[x+1] < best {
+				best = prev[x+1]
+			}
+			row[x] = energy[y*g.w+x] + best
+		}
+	}
+
+	seam := make([]int, g.h)
+	last := (g.h - 1) * g.w
+	for x := 1; x < g.w; x++ {
+		if cost[last+x] < cost[last+seam[g.h-1]] {
+			seam[g.h-1] = x
+		}
+	}
+
+	for y := g.h - 2; y >= 0; y-- {
+		prev := seam[y+1]
+		seam[y] = prev
+		for _, x := range []int{prev - 1, prev + 1} {
+			if x >= 0 && x < g.w && cost[y*g.w+x] < cost[y*g.w+seam[y]] {
+				seam[y] = x
+			}
+		}
+	}
+
+	return seam
+}
+
+func (g *grid) removeSeam(seam []int) {
+	w := g.w - 1
+	for y := 0; y < g.h; y++ {
+		src := y * g.w
+		dst := y * w
+		x := seam[y]
+
+		copy(g.pix[dst:dst+x], g.pix[src:src+x])
+		copy(g.pix[dst+x:dst+w], g.pix[src+x+1:src+g.w])
+		copy(g.protect[dst:dst+x], g.protect[src:src+x])
+		copy(g.protect[dst+x:dst+w], g.protect[src+x+1:src+g.w])
+		if g.origin != nil {
+			copy(g.origin[dst:dst+x], g.origin[src:src+x])
+			copy(g.origin[dst+x:dst+w], g.origin[src+x+1:src+g.w])
+		}
+		if g.energy != nil {
+			copy(g.energy[dst:dst+x], g.energy[src:src+x])
+			copy(g.energy[dst+x:dst+w], g.energy[src+x+1:src+g.w])
+		}
+	}
+
+	g.w = w
+	g.pix = g.pix[:w*g.h]
+	g.protect = g.protect[:w*g.h]
+	if g.origin != nil {
+		g.origin = g.origin[:w*g.h]
+	}
+
+	// Only pixels next to the seam, or below and above a shifted neighbor,
+	// change their energy. The seam moves at most one column per row.
+	if g.energy != nil {
+		g.energy = g.energy[:w*g.h]
+		for y := 0; y < g.h; y++ {
+			for x := max(seam[y]-2, 0); x <= min(seam[y]+1, w-1); x++ {
+				g.energy[y*w+x] = g.energyAt(x, y)
+			}
+		}
+	}
+}
+
+// energyAt computes the dual-gradient energy of a pixel.
+func (g *grid) energyAt(x, y int) float64 {
+	left := g.pix[y*g.w+max(x-1, 0)]
+	right := g.pix[y*g.w+min(x+1, g.w-1)]
+	up := g.pix[max(y-1, 0)*g.w+x]
+	down := g.pix[min(y+1, g.h-1)*g.w+x]
+
+	e := diff(left, right) + diff(up, down)
+	if g.protect[y*g.w+x] {
+		e += protectEnergy
+	}
+
+	return e
+}
+
+func diff(a, b color.NRGBA) float64 {
+	dr := float64(a.R) - float64(b.R)
+	dg := float64(a.G) - float64(b.G)
+	db := float64(a.B) - float64(b.B)
+	da := float64(a.A) - float64(b.A)
+
+	return math.Sqrt(dr*dr + dg*dg + db*db + da*da)
+}
+
+func average(a, b color.NRGBA) color.NRGBA {
+	return color.NRGBA{
+		R: uint8((uint16(a.R) + uint16(b.R)) / 2),
+		G: uint8((uint16(a.G) + uint16(b.G)) / 2),
+		B: uint8((uint16(a.B) + uint16(b.B)) / 2),
+		A: uint8((uint16(a.A) + uint16(b.A)) / 2),
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
@@ -0,0 +1,71 @@
+package liquidresize_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/liquidresize"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stripes returns a flat gray image with a column of alternating black and
+// white pixels at x, which has by far the highest energy.
+func stripes(w, h, x int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for py := 0; py < h; py++ {
+		for px := 0; px < w; px++ {
+			c := color.NRGBA{R: 128, G: 128, B: 128, A: 255}
+			if px == x {
+				c = color.NRGBA{A: 255}
+				if py%2 == 0 {
+					c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+				}
+			}
+			img.SetNRGBA(px, py, c)
+		}
+	}
+
+	return img
+}
+
+func TestLiquidResizeImage_ExactSize(t *testing.T) {
+	img := stripes(40, 30, 20)
+
+	for _, size := range []image.Point{{25, 30}, {40, 18}, {55, 30}, {40, 45}, {31, 50}} {
+		params := liquidresize.LiquidResizeParams{Width: size.X, Height: size.Y}
+		out, err := params.LiquidResizeImage(img)
+		assert.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, size.X, size.Y), out.Bounds(), "%v", size)
+	}
+}
+
+func TestLiquidResizeImage_KeepsHighEnergyColumn(t *testing.T) {
+	img := stripes(40, 30, 20)
+
+	params := liquidresize.LiquidResizeParams{Width: 25}
+	out, err := params.LiquidResizeImage(img)
+	assert.NoError(t, err)
+
+	dst := out.(*image.NRGBA)
+	found := false
+	for x := 0; x < dst.Rect.Dx() && !found; x++ {
+		found = true
+		for y := 0; y < dst.Rect.Dy(); y++ {
+			if dst.NRGBAAt(x, y) != img.NRGBAAt(20, y) {
+				found = false
+				break
+			}
+		}
+	}
+	assert.True(t, found, "the striped column was carved away")
+}
+
+func TestLiquidResizeImage_TooManySeams(t *testing.T) {
+	// Only the bounds are looked at before the request is rejected.
+	img := &image.NRGBA{Rect: image.Rect(0, 0, 8000, 8000)}
+
+	params := liquidresize.LiquidResizeParams{Width: 1}
+	_, err := params.LiquidResizeImage(img)
+	assert.ErrorContains(t, err, "too many seams")
+}
@@ -0,0 +1,45 @@
+package lut
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/cube"
+
+	"github.com/disintegration/imaging"
+)
+
+const tetrahedralInterpolation = "tetrahedral"
+
+type LUTParams struct {
+	LUTName       string  `json:"lut_name" validate:"required,max=100"`
+	Interpolation string  `json:"interpolation" validate:"omitempty,oneof=trilinear tetrahedral"`
+	Intensity     float64 `json:"intensity" validate:"min=0,max=100"`
+}
+
+// LUTImage grades the image through the 3D lookup table and mixes the result
+// with the original by Intensity percent. An intensity of 0 is treated as
+// 100.
+func (params *LUTParams) LUTImage(img image.Image, table *cube.LUT) (image.Image, error) {
+	intensity := params.Intensity / 100
+	if intensity == 0 {
+		intensity = 1
+	}
+
+	lookup := table.Trilinear
+	if params.Interpolation == tetrahedralInterpolation {
+		lookup = table.Tetrahedral
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		in := [3]float64{float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255}
+		out := lookup(in)
+
+		var px [3]uint8
+		for i := range px {
+			px[i] = colors.Clamp8((in[i] + (out[i]-in[i])*intensity) * 255)
+		}
+
+		return color.NRGBA{R: px[0], G: px[1], B: px[2], A: c.A}
+	}), nil
+}
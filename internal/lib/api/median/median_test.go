@@ -0,0 +1,67 @@
+package median_test
+
+import (
+	"image"
+	"math/rand"
+	"online-photo-editor/internal/lib/api/median"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bruteMedian sorts the window of every pixel, repeating the border pixels
+// outside the image.
+func bruteMedian(src *image.NRGBA, r int) *image.NRGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewNRGBA(src.Bounds())
+	copy(dst.Pix, src.Pix)
+
+	clamp := func(v, n int) int {
+		return min(max(v, 0), n-1)
+	}
+
+	values := make([]int, 0, (2*r+1)*(2*r+1))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for c := 0; c < 3; c++ {
+				values = values[:0]
+				for dy := -r; dy <= r; dy++ {
+					for dx := -r; dx <= r; dx++ {
+						values = append(values, int(src.Pix[clamp(y+dy, h)*src.Stride+clamp(x+dx, w)*4+c]))
+					}
+				}
+				sort.Ints(values)
+				dst.Pix[y*dst.Stride+x*4+c] = uint8(values[len(values)/2])
+			}
+		}
+	}
+
+	return dst
+}
+
+func TestMedianImage_MatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	src := image.NewNRGBA(image.Rect(0, 0, 23, 17))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(rng.Intn(256))
+	}
+
+	for _, r := range []int{1, 2, 3, 12} {
+		out, err := (&median.MedianParams{Radius: r}).MedianImage(src)
+		assert.NoError(t, err)
+		assert.Equal(t, bruteMedian(src, r).Pix, out.(*image.NRGBA).Pix, "radius %d", r)
+	}
+}
+
+func TestMedianImage_RemovesSpeckle(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 9, 9))
+	for i := range src.Pix {
+		src.Pix[i] = 200
+	}
+	src.Pix[src.PixOffset(4, 4)] = 0
+
+	out, err := (&median.MedianParams{Radius: 1}).MedianImage(src)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(200), out.(*image.NRGBA).Pix[src.PixOffset(4, 4)])
+}
@@ -0,0 +1,104 @@
+package morphology_test
+
+import (
+	"image"
+	"math"
+	"math/rand"
+	"online-photo-editor/internal/lib/api/morphology"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// inElement reports whether the offset belongs to the structuring element of
+// radius r.
+func inElement(element string, dx, dy, r int) bool {
+	switch element {
+	case "cross":
+		return dx == 0 || dy == 0
+	case "disk":
+		return math.Abs(float64(dx)) <= math.Floor(math.Sqrt(float64(r*r-dy*dy)))
+	default:
+		return true
+	}
+}
+
+// naive takes the maximum or minimum over the element at every pixel,
+// ignoring the offsets outside the image.
+func naive(src *image.NRGBA, element string, r int, dilate bool) []uint8 {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	out := append([]uint8(nil), src.Pix...)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			for c := 0; c < 3; c++ {
+				best := uint8(255)
+				if dilate {
+					best = 0
+				}
+				for dy := -r; dy <= r; dy++ {
+					for dx := -r; dx <= r; dx++ {
+						if !inElement(element, dx, dy, r) || x+dx < 0 || x+dx >= w || y+dy < 0 || y+dy >= h {
+							continue
+						}
+						v := src.Pix[(y+dy)*src.Stride+(x+dx)*4+c]
+						if dilate == (v > best) {
+							best = v
+						}
+					}
+				}
+				out[y*src.Stride+x*4+c] = best
+			}
+		}
+	}
+
+	return out
+}
+
+func TestMorphologyImage_MatchesNaive(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	src := image.NewNRGBA(image.Rect(0, 0, 19, 14))
+	for i := range src.Pix {
+		src.Pix[i] = uint8(rng.Intn(256))
+	}
+
+	for _, element := range []string{"square", "disk", "cross"} {
+		for _, size := range []int{3, 5, 9, 31} {
+			params := &morphology.MorphologyParams{Element: element, Size: size}
+
+			dilated, err := params.MorphologyImage(src, morphology.Dilate)
+			assert.NoError(t, err)
+			assert.Equal(t, naive(src, element, size/2, true), dilated.(*image.NRGBA).Pix, "dilate %s %d", element, size)
+
+			eroded, err := params.MorphologyImage(src, morphology.Erode)
+			assert.NoError(t, err)
+			assert.Equal(t, naive(src, element, size/2, false), eroded.(*image.NRGBA).Pix, "erode %s %d", element, size)
+		}
+	}
+}
+
+func TestMorphologyImage_OpenRemovesSpeck(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 9, 9))
+	for i := range src.Pix {
+		src.Pix[i] = 255
+		if i%4 != 3 {
+			src.Pix[i] = 0
+		}
+	}
+	i := src.PixOffset(4, 4)
+	src.Pix[i], src.Pix[i+1], src.Pix[i+2] = 255, 255, 255
+
+	out, err := (&morphology.MorphologyParams{}).MorphologyImage(src, morphology.Open)
+	assert.NoError(t, err)
+	assert.Equal(t, []uint8{0, 0, 0, 255}, out.(*image.NRGBA).Pix[i:i+4])
+}
+
+func TestMorphologyImage_Errors(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+
+	_, err := (&morphology.MorphologyParams{Size: 4}).MorphologyImage(src, morphology.Dilate)
+	assert.Error(t, err)
+
+	_, err = (&morphology.MorphologyParams{}).MorphologyImage(src, "blur")
+	assert.Error(t, err)
+}
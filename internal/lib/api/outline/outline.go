@@ -0,0 +1,23 @@
+package outline
+
+import (
+	"image"
+	"online-photo-editor/internal/lib/api/convolve"
+)
+
+type OutlineParams struct{}
+
+// OutlineImage keeps only the outlines of shapes in their own color on a
+// black background.
+func (params *OutlineParams) OutlineImage(img image.Image) (image.Image, error) {
+	convolveParams := convolve.ConvolveParams{
+		Kernel: [][]float64{
+			{-1, -1, -1},
+			{-1, 8, -1},
+			{-1, -1, -1},
+		},
+		Divisor: 1,
+	}
+
+	return convolveParams.ConvolveImage(img)
+}
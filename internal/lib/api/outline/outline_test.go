@@ -0,0 +1,63 @@
+package outline_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/outline"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// step returns an 8 x 3 image whose left half is gray lo and right half is
+// gray hi, a vertical edge between x = 3 and x = 4.
+func step(lo, hi uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 8; x++ {
+			v := lo
+			if x >= 4 {
+				v = hi
+			}
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+// middle returns the gray values of the middle row, checking that the
+// pixels are gray and opaque.
+func middle(t *testing.T, img image.Image) []uint8 {
+	t.Helper()
+
+	var values []uint8
+	for x := 0; x < img.Bounds().Dx(); x++ {
+		c := color.NRGBAModel.Convert(img.At(x, 1)).(color.NRGBA)
+		require.Equal(t, color.NRGBA{R: c.R, G: c.R, B: c.R, A: 255}, c, "pixel %d", x)
+		values = append(values, c.R)
+	}
+
+	return values
+}
+
+func TestOutlineImage(t *testing.T) {
+	tests := []struct {
+		name string
+		src  *image.NRGBA
+		want []uint8
+	}{
+		{"flat areas turn black", step(120, 120), []uint8{0, 0, 0, 0, 0, 0, 0, 0}},
+		{"the bright side of an edge is kept", step(0, 50), []uint8{0, 0, 0, 0, 150, 0, 0, 0}},
+		{"on either side", step(50, 0), []uint8{0, 0, 0, 150, 0, 0, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := (&outline.OutlineParams{}).OutlineImage(tt.src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, middle(t, out))
+		})
+	}
+}
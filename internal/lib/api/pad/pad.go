@@ -0,0 +1,140 @@
+package pad
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/gravity"
+
+	"github.com/disintegration/imaging"
+)
+
+// maxDimension is the largest output width or height.
+const maxDimension = 8000
+
+const (
+	transparentFill = "transparent"
+	edgeFill        = "edge"
+	mirrorFill      = "mirror"
+)
+
+type PadParams struct {
+	Top     int    `json:"top" validate:"min=0,max=8000"`
+	Right   int    `json:"right" validate:"min=0,max=8000"`
+	Bottom  int    `json:"bottom" validate:"min=0,max=8000"`
+	Left    int    `json:"left" validate:"min=0,max=8000"`
+	Width   int    `json:"width" validate:"min=0,max=8000"`
+	Height  int    `json:"height" validate:"min=0,max=8000"`
+	Gravity string `json:"gravity" validate:"omitempty,oneof=center north south east west northeast northwest southeast southwest"`
+	Fill    string `json:"fill" validate:"omitempty,oneof=color transparent edge mirror"`
+	Color   string `json:"color" validate:"omitempty,max=20"`
+}
+
+// margins returns the amount to add on each side. A target width or height
+// takes precedence over the per-side amounts for that axis.
+func (params *PadParams) margins(b image.Rectangle) (top, right, bottom, left int, err error) {
+	const op = "api.pad.margins"
+
+	top, right, bottom, left = params.Top, params.Right, params.Bottom, params.Left
+
+	if params.Width == 0 && params.Height == 0 {
+		return top, right, bottom, left, nil
+	}
+
+	if params.Width > 0 && params.Width < b.Dx() || params.Height > 0 && params.Height < b.Dy() {
+		return 0, 0, 0, 0, fmt.Errorf("%s: target size is smaller than the image", op)
+	}
+
+	anchor, err := gravity.Parse(params.Gravity)
+	if err != nil {
+		return 0, 0, 0, 0, err
+	}
+
+	w, h := max(params.Width, b.Dx()), max(params.Height, b.Dy())
+	pt := gravity.Point(image.Rect(0, 0, w, h), b.Dx(), b.Dy(), anchor)
+
+	if params.Width > 0 {
+		left, right = pt.X, w-b.Dx()-pt.X
+	}
+	if params.Height > 0 {
+		top, bottom = pt.Y, h-b.Dy()-pt.Y
+	}
+
+	return top, right, bottom, left, nil
+}
+
+// PadImage grows the canvas around img and fills the new area with a solid
+// color, transparency, the repeated edge pixels or a mirror of the image.
+func (params *PadParams) PadImage(img image.Image) (image.Image, error) {
+	const op = "api.pad.PadImage"
+
+	b := img.Bounds()
+	top, right, bottom, left, err := params.margins(b)
+	if err != nil {
+		return nil, err
+	}
+
+	w, h := b.Dx()+left+right, b.Dy()+top+bottom
+	if w > maxDimension || h > maxDimension {
+		return nil, fmt.Errorf("%s: canvas of %dx%d exceeds %d pixels per side", op, w, h, maxDimension)
+	}
+
+	src := imaging.Clone(img)
+
+	switch params.Fill {
+	case edgeFill, mirrorFill:
+		dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+		mapCoord := clamp
+		if params.Fill == mirrorFill {
+			mapCoord = reflect
+		}
+
+		for y := 0; y < h; y++ {
+			sy := mapCoord(y-top, b.Dy())
+			for x := 0; x < w; x++ {
+				sx := mapCoord(x-left, b.Dx())
+				copy(dst.Pix[y*dst.Stride+x*4:y*dst.Stride+x*4+4], src.Pix[sy*src.Stride+sx*4:sy*src.Stride+sx*4+4])
+			}
+		}
+
+		return dst, nil
+	default:
+		var fill color.Color = color.Transparent
+		if params.Fill != transparentFill {
+			c := "white"
+			if params.Color != "" {
+				c = params.Color
+			}
+			parsed, err := colors.Parse(c)
+			if err != nil {
+				return nil, err
+			}
+			fill = parsed
+		}
+
+		return imaging.Paste(imaging.New(w, h, fill), src, image.Pt(left, top)), nil
+	}
+}
+
+func clamp(v, size int) int {
+	return min(max(v, 0), size-1)
+}
+
+// reflect mirrors v into [0, size) without repeating the edge pixel.
+func reflect(v, size int) int {
+	if size == 1 {
+		return 0
+	}
+
+	period := 2 * (size - 1)
+	v %= period
+	if v < 0 {
+		v += period
+	}
+	if v >= size {
+		v = period - v
+	}
+
+	return v
+}
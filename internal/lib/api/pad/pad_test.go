@@ -0,0 +1,158 @@
+package pad_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/pad"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red   = color.NRGBA{R: 255, A: 255}
+	green = color.NRGBA{G: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// row returns a one pixel high image with the given colors.
+func row(colors ...color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(colors), 1))
+	for x, c := range colors {
+		img.SetNRGBA(x, 0, c)
+	}
+
+	return img
+}
+
+func pixels(t *testing.T, img image.Image) []color.NRGBA {
+	t.Helper()
+
+	b := img.Bounds()
+	require.Equal(t, 1, b.Dy())
+
+	out := make([]color.NRGBA, 0, b.Dx())
+	for x := b.Min.X; x < b.Max.X; x++ {
+		out = append(out, color.NRGBAModel.Convert(img.At(x, b.Min.Y)).(color.NRGBA))
+	}
+
+	return out
+}
+
+func TestPadImage_Fill(t *testing.T) {
+	src := row(red, green, blue)
+
+	tests := []struct {
+		name   string
+		params pad.PadParams
+		want   []color.NRGBA
+	}{
+		{"color defaults to white", pad.PadParams{Left: 1, Right: 2},
+			[]color.NRGBA{white, red, green, blue, white, white}},
+		{"color", pad.PadParams{Left: 1, Color: "#00ff00"},
+			[]color.NRGBA{green, red, green, blue}},
+		{"transparent", pad.PadParams{Right: 1, Fill: "transparent"},
+			[]color.NRGBA{red, green, blue, {}}},
+		{"edge repeats the outer pixels", pad.PadParams{Left: 2, Right: 3, Fill: "edge"},
+			[]color.NRGBA{red, red, red, green, blue, blue, blue, blue}},
+		{"mirror does not repeat the edge pixel", pad.PadParams{Left: 3, Right: 3, Fill: "mirror"},
+			[]color.NRGBA{green, blue, green, red, green, blue, green, red, green}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.params.PadImage(src)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, pixels(t, out))
+		})
+	}
+}
+
+func TestPadImage_EdgeFillCorners(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	src.SetNRGBA(0, 0, red)
+	src.SetNRGBA(1, 0, green)
+	src.SetNRGBA(0, 1, blue)
+	src.SetNRGBA(1, 1, white)
+
+	params := pad.PadParams{Top: 2, Right: 2, Bottom: 2, Left: 2, Fill: "edge"}
+	out, err := params.PadImage(src)
+	require.NoError(t, err)
+	require.Equal(t, image.Pt(6, 6), out.Bounds().Size())
+
+	// Each corner of the canvas repeats the nearest corner pixel.
+	assert.Equal(t, red, out.At(0, 0))
+	assert.Equal(t, green, out.At(5, 0))
+	assert.Equal(t, blue, out.At(0, 5))
+	assert.Equal(t, white, out.At(5, 5))
+}
+
+func TestPadImage_Gravity(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			src.SetNRGBA(x, y, red)
+		}
+	}
+
+	tests := []struct {
+		gravity string
+		want    image.Point
+	}{
+		{"", image.Pt(2, 1)},
+		{"center", image.Pt(2, 1)},
+		{"northwest", image.Pt(0, 0)},
+		{"north", image.Pt(2, 0)},
+		{"east", image.Pt(4, 1)},
+		{"southeast", image.Pt(4, 2)},
+		{"southwest", image.Pt(0, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gravity, func(t *testing.T) {
+			params := pad.PadParams{Width: 6, Height: 4, Gravity: tt.gravity}
+			out, err := params.PadImage(src)
+			require.NoError(t, err)
+			require.Equal(t, image.Pt(6, 4), out.Bounds().Size())
+
+			placed := image.Rectangle{Min: tt.want, Max: tt.want.Add(image.Pt(2, 2))}
+			for y := 0; y < 4; y++ {
+				for x := 0; x < 6; x++ {
+					want := white
+					if image.Pt(x, y).In(placed) {
+						want = red
+					}
+					assert.Equal(t, want, out.At(x, y), "pixel (%d, %d)", x, y)
+				}
+			}
+		})
+	}
+}
+
+func TestPadImage_TargetOverridesSides(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+
+	// Only the width is given, so the per-side amounts still apply vertically.
+	params := pad.PadParams{Width: 10, Top: 3, Left: 100, Gravity: "west"}
+	out, err := params.PadImage(src)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(10, 5), out.Bounds().Size())
+}
+
+func TestPadImage_Errors(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 100, 100))
+
+	_, err := (&pad.PadParams{Width: 50}).PadImage(src)
+	assert.ErrorContains(t, err, "smaller than the image")
+
+	_, err = (&pad.PadParams{Left: 8000}).PadImage(src)
+	assert.ErrorContains(t, err, "exceeds 8000 pixels per side")
+
+	_, err = (&pad.PadParams{Height: 8000, Top: 10}).PadImage(src)
+	assert.NoError(t, err)
+
+	_, err = (&pad.PadParams{Top: 10, Color: "nope"}).PadImage(src)
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package pixelate
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+type PixelateParams struct {
+	BlockSize int `json:"block_size" validate:"required,min=2,max=512"`
+}
+
+// PixelateImage replaces every BlockSize x BlockSize block with its average
+// color.
+func (params *PixelateParams) PixelateImage(img image.Image) (image.Image, error) {
+	return Pixelate(imaging.Clone(img), params.BlockSize), nil
+}
+
+// Pixelate averages img in place over square blocks of the given size,
+// starting at the top-left corner. Colors are weighted by alpha so
+// transparent pixels do not bleed into their block.
+func Pixelate(img *image.NRGBA, blockSize int) *image.NRGBA {
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+
+	for by := 0; by < h; by += blockSize {
+		for bx := 0; bx < w; bx += blockSize {
+			x1, y1 := min(bx+blockSize, w), min(by+blockSize, h)
+
+			var r, g, b, a, n int
+			for y := by; y < y1; y++ {
+				row := img.Pix[y*img.Stride+bx*4 : y*img.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					pa := int(row[i+3])
+					r += int(row[i]) * pa
+					g += int(row[i+1]) * pa
+					b += int(row[i+2]) * pa
+					a += pa
+					n++
+				}
+			}
+
+			var px [4]uint8
+			if a > 0 {
+				px = [4]uint8{uint8((r + a/2) / a), uint8((g + a/2) / a), uint8((b + a/2) / a), uint8((a + n/2) / n)}
+			}
+			for y := by; y < y1; y++ {
+				row := img.Pix[y*img.Stride+bx*4 : y*img.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					copy(row[i:i+4], px[:])
+				}
+			}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,28 @@
+package posterize
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/disintegration/imaging"
+)
+
+type PosterizeParams struct {
+	Levels int `json:"levels" validate:"required,min=2,max=256"`
+}
+
+// PosterizeImage reduces every color channel to the given number of evenly
+// spaced levels.
+func (params *PosterizeParams) PosterizeImage(img image.Image) (image.Image, error) {
+	steps := float64(params.Levels - 1)
+
+	var lut [256]uint8
+	for v := range lut {
+		lut[v] = uint8(math.Round(math.Round(float64(v)*steps/255) * 255 / steps))
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[c.R], G: lut[c.G], B: lut[c.B], A: c.A}
+	}), nil
+}
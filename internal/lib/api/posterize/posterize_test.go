@@ -0,0 +1,74 @@
+package posterize_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/posterize"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ramp returns a 256 x 1 image going through every gray value, half
+// transparent.
+func ramp() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 256, 1))
+	for x := 0; x < 256; x++ {
+		v := uint8(x)
+		img.SetNRGBA(x, 0, color.NRGBA{R: v, G: v, B: v, A: 128})
+	}
+
+	return img
+}
+
+func TestPosterizeImage_Levels(t *testing.T) {
+	tests := []struct {
+		levels int
+		want   []uint8
+	}{
+		{2, []uint8{0, 255}},
+		{3, []uint8{0, 128, 255}},
+		{4, []uint8{0, 85, 170, 255}},
+	}
+
+	for _, tt := range tests {
+		params := posterize.PosterizeParams{Levels: tt.levels}
+		out, err := params.PosterizeImage(ramp())
+		require.NoError(t, err)
+
+		var values []uint8
+		seen := map[uint8]bool{}
+		for x := 0; x < 256; x++ {
+			c := color.NRGBAModel.Convert(out.At(x, 0)).(color.NRGBA)
+			assert.Equal(t, c.R, c.G)
+			assert.Equal(t, c.R, c.B)
+			assert.Equal(t, uint8(128), c.A)
+			if !seen[c.R] {
+				seen[c.R] = true
+				values = append(values, c.R)
+			}
+		}
+
+		assert.Equal(t, tt.want, values, "levels %d", tt.levels)
+	}
+}
+
+func TestPosterizeImage_RoundsToNearestLevel(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 42, G: 43, B: 200, A: 255})
+
+	params := posterize.PosterizeParams{Levels: 4}
+	out, err := params.PosterizeImage(img)
+	require.NoError(t, err)
+
+	assert.Equal(t, color.NRGBA{R: 0, G: 85, B: 170, A: 255}, out.At(0, 0))
+}
+
+func TestPosterizeImage_AllLevelsIsIdentity(t *testing.T) {
+	params := posterize.PosterizeParams{Levels: 256}
+	out, err := params.PosterizeImage(ramp())
+	require.NoError(t, err)
+
+	assert.Equal(t, ramp().Pix, out.(*image.NRGBA).Pix)
+}
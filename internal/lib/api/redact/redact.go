@@ -0,0 +1,82 @@
+package redact
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/pixelate"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/mask"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	MethodPixelate = "pixelate"
+	MethodBlur     = "blur"
+	MethodFill     = "fill"
+)
+
+const (
+	defaultBlockSize = 16
+	defaultSigma     = 20
+)
+
+type RedactParams struct {
+	Regions   []mask.Region `json:"regions" validate:"required,min=1,max=64,dive"`
+	Method    string        `json:"method" validate:"omitempty,oneof=pixelate blur fill"`
+	BlockSize int           `json:"block_size" validate:"omitempty,min=2,max=512"`
+	Sigma     float64       `json:"sigma" validate:"omitempty,gt=0,max=100"`
+	Color     string        `json:"color" validate:"omitempty,max=20"`
+}
+
+// RedactImage hides the given regions by pixelating, blurring or filling
+// them, leaving the rest of the image untouched. Method defaults to
+// pixelate, BlockSize to 16, Sigma to 20 and Color to black.
+func (params *RedactParams) RedactImage(img image.Image) (image.Image, error) {
+	const op = "api.redact.RedactImage"
+
+	src := imaging.Clone(img)
+
+	m, err := mask.Regions(params.Regions, src.Bounds())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	area := mask.Bounds(m)
+	if area.Empty() {
+		return src, nil
+	}
+
+	overlay := imaging.Clone(src)
+	switch params.Method {
+	case "", MethodPixelate:
+		blockSize := params.BlockSize
+		if blockSize == 0 {
+			blockSize = defaultBlockSize
+		}
+		overlay = imaging.Paste(overlay, pixelate.Pixelate(imaging.Crop(src, area), blockSize), area.Min)
+	case MethodBlur:
+		sigma := params.Sigma
+		if sigma == 0 {
+			sigma = defaultSigma
+		}
+		// Blur a margin around the regions too so the edges are not
+		// darkened by the missing neighbours.
+		margin := int(3 * sigma)
+		padded := area.Inset(-margin).Intersect(src.Bounds())
+		blurred := imaging.Crop(imaging.Blur(imaging.Crop(src, padded), sigma), area.Sub(padded.Min))
+		overlay = imaging.Paste(overlay, blurred, area.Min)
+	case MethodFill:
+		fill := color.NRGBA{A: 255}
+		if params.Color != "" {
+			if fill, err = colors.Parse(params.Color); err != nil {
+				return nil, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		overlay = imaging.Paste(overlay, imaging.New(area.Dx(), area.Dy(), fill), area.Min)
+	default:
+		return nil, fmt.Errorf("%s: unknown method %q", op, params.Method)
+	}
+
+	return mask.Blend(src, overlay, m), nil
+}
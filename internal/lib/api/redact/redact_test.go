@@ -0,0 +1,64 @@
+package redact_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/redact"
+	"online-photo-editor/internal/lib/geometry"
+	"online-photo-editor/internal/lib/mask"
+	"testing"
+
+	"github.com/disintegration/imaging"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactImage_CoversHugeRegions(t *testing.T) {
+	regions := map[string]mask.Region{
+		"rect":    {Rect: &geometry.Rect{X: 0, Y: 0, Width: 1e6, Height: 1e6}},
+		"ellipse": {Ellipse: &mask.Ellipse{X: 10, Y: 10, RadiusX: 1e6, RadiusY: 1e6}},
+		"polygon": {Polygon: []geometry.Point{{X: -1e6, Y: -1e6}, {X: 1e6, Y: -1e6}, {X: 0, Y: 1e6}}},
+	}
+
+	for name, region := range regions {
+		t.Run(name, func(t *testing.T) {
+			src := imaging.New(20, 20, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+			params := &redact.RedactParams{Regions: []mask.Region{region}, Method: redact.MethodFill}
+
+			out, err := params.RedactImage(src)
+			assert.NoError(t, err)
+
+			dst := out.(*image.NRGBA)
+			for i := 0; i < len(dst.Pix); i += 4 {
+				if dst.Pix[i] != 0 {
+					t.Fatalf("pixel %d is not redacted: %v", i/4, dst.Pix[i:i+4])
+				}
+			}
+		})
+	}
+}
+
+func TestRedactImage_RejectsFarCoordinates(t *testing.T) {
+	src := imaging.New(20, 20, color.White)
+	params := &redact.RedactParams{Regions: []mask.Region{
+		{Polygon: []geometry.Point{{X: -1e8, Y: -1e8}, {X: 1e8, Y: -1e8}, {X: 0, Y: 1e8}}},
+	}}
+
+	_, err := params.RedactImage(src)
+	assert.Error(t, err)
+}
+
+func TestRedactImage_UnionOfRegions(t *testing.T) {
+	src := imaging.New(20, 10, color.White)
+	// Two overlapping rectangles, the second given in the opposite winding.
+	params := &redact.RedactParams{Method: redact.MethodFill, Regions: []mask.Region{
+		{Rect: &geometry.Rect{X: 0, Y: 0, Width: 12, Height: 10}},
+		{Polygon: []geometry.Point{{X: 8, Y: 0}, {X: 8, Y: 10}, {X: 20, Y: 10}, {X: 20, Y: 0}}},
+	}}
+
+	out, err := params.RedactImage(src)
+	assert.NoError(t, err)
+
+	for _, x := range []int{2, 10, 18} {
+		assert.Equal(t, color.NRGBA{A: 255}, out.(*image.NRGBA).NRGBAAt(x, 5), "x=%d", x)
+	}
+}
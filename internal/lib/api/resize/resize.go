@@ -1,16 +1,176 @@
 package resize
 
 import (
+	"fmt"
 	"image"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/gravity"
 
 	"github.com/disintegration/imaging"
 )
 
+// maxDimension is the largest output width or height.
+const maxDimension = 8000
+
+const (
+	exactMode     = "exact"
+	fitMode       = "fit"
+	fillMode      = "fill"
+	thumbnailMode = "thumbnail"
+	padMode       = "pad"
+	scaleMode     = "scale"
+)
+
+var filters = map[string]imaging.ResampleFilter{
+	"nearest":     imaging.NearestNeighbor,
+	"box":         imaging.Box,
+	"linear":      imaging.Linear,
+	"catmull_rom": imaging.CatmullRom,
+	"lanczos":     imaging.Lanczos,
+}
+
 type ResizeParams struct {
-	Width  int `json:"width" validate:"required,min=0,max=8000"`
-	Height int `json:"height" validate:"required,min=0,max=8000"`
+	Width      int     `json:"width" validate:"min=0,max=8000"`
+	Height     int     `json:"height" validate:"min=0,max=8000"`
+	Mode       string  `json:"mode" validate:"omitempty,oneof=exact fit fill thumbnail pad scale"`
+	Percent    float64 `json:"percent" validate:"min=0,max=1000"`
+	Gravity    string  `json:"gravity" validate:"omitempty,oneof=center north south east west northeast northwest southeast southwest"`
+	Background string  `json:"background" validate:"omitempty,max=20"`
+	Filter     string  `json:"filter" validate:"omitempty,oneof=nearest box linear catmull_rom lanczos"`
+	NoUpscale  bool    `json:"no_upscale"`
+}
+
+func (params *ResizeParams) validate() error {
+	const op = "api.resize.validate"
+
+	switch params.Mode {
+	case "", exactMode:
+		if params.Width == 0 && params.Height == 0 {
+			return fmt.Errorf("%s: width or height must be set", op)
+		}
+	case scaleMode:
+		if params.Percent == 0 {
+			return fmt.Errorf("%s: percent must be set in scale mode", op)
+		}
+	default:
+		if params.Width == 0 || params.Height == 0 {
+			return fmt.Errorf("%s: width and height must be set in %s mode", op, params.Mode)
+		}
+	}
+
+	return nil
 }
 
 func (params *ResizeParams) ResizeImage(img image.Image) (image.Image, error) {
-	return imaging.Resize(img, params.Width, params.Height, imaging.Lanczos), nil
+	const op = "api.resize.ResizeImage"
+
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	filter := imaging.Lanczos
+	if params.Filter != "" {
+		filter = filters[params.Filter]
+	}
+
+	anchor, err := gravity.Parse(params.Gravity)
+	if err != nil {
+		return nil, err
+	}
+
+	srcW, srcH := img.Bounds().Dx(), img.Bounds().Dy()
+
+	switch params.Mode {
+	case fitMode, padMode:
+		scale := params.limit(math.Min(float64(params.Width)/float64(srcW), float64(params.Height)/float64(srcH)))
+		fitted := imaging.Resize(img, scaled(srcW, scale), scaled(srcH, scale), filter)
+		if params.Mode == fitMode {
+			return fitted, nil
+		}
+
+		bg := "white"
+		if params.Background != "" {
+			bg = params.Background
+		}
+		fill, err := colors.Parse(bg)
+		if err != nil {
+			return nil, err
+		}
+
+		canvas := imaging.New(params.Width, params.Height, fill)
+		pos := gravity.Point(canvas.Bounds(), fitted.Bounds().Dx(), fitted.Bounds().Dy(), anchor)
+
+		return imaging.Overlay(canvas, fitted, pos, 1), nil
+	case fillMode, thumbnailMode:
+		if params.Mode == thumbnailMode {
+			anchor = imaging.Center
+		}
+
+		scale := params.limit(math.Max(float64(params.Width)/float64(srcW), float64(params.Height)/float64(srcH)))
+		w := min(params.Width, scaled(srcW, scale))
+		h := min(params.Height, scaled(srcH, scale))
+
+		// Crop the covered part of the source first, so a very wide or tall
+		// image is never scaled up as a whole.
+		cropW := min(srcW, scaled(w, 1/scale))
+		cropH := min(srcH, scaled(h, 1/scale))
+
+		return imaging.Resize(imaging.CropAnchor(img, cropW, cropH, anchor), w, h, filter), nil
+	case scaleMode:
+		scale := params.limit(params.Percent / 100)
+		w, h := scaled(srcW, scale), scaled(srcH, scale)
+		if w > maxDimension || h > maxDimension {
+			return nil, fmt.Errorf("%s: output of %dx%d exceeds %d pixels per side", op, w, h, maxDimension)
+		}
+
+		return imaging.Resize(img, w, h, filter), nil
+	default:
+		w, h := params.Width, params.Height
+		if params.NoUpscale {
+			// Shrink both sides by the same factor so the requested aspect
+			// ratio is kept.
+			factor := 1.0
+			if w > 0 {
+				factor = math.Min(factor, float64(srcW)/float64(w))
+			}
+			if h > 0 {
+				factor = math.Min(factor, float64(srcH)/float64(h))
+			}
+			if w > 0 {
+				w = scaled(w, factor)
+			}
+			if h > 0 {
+				h = scaled(h, factor)
+			}
+		}
+
+		// A missing side follows the aspect ratio of the source and may
+		// grow past the limit for very wide or tall images.
+		outW, outH := w, h
+		if outW == 0 {
+			outW = scaled(srcW, float64(h)/float64(srcH))
+		}
+		if outH == 0 {
+			outH = scaled(srcH, float64(w)/float64(srcW))
+		}
+		if outW > maxDimension || outH > maxDimension {
+			return nil, fmt.Errorf("%s: output of %dx%d exceeds %d pixels per side", op, outW, outH, maxDimension)
+		}
+
+		return imaging.Resize(img, w, h, filter), nil
+	}
+}
+
+// limit caps the scale factor at 1 when upscaling is disabled.
+func (params *ResizeParams) limit(scale float64) float64 {
+	if params.NoUpscale {
+		return math.Min(scale, 1)
+	}
+
+	return scale
+}
+
+func scaled(size int, scale float64) int {
+	return max(1, int(math.Round(float64(size)*scale)))
 }
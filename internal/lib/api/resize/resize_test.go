@@ -0,0 +1,57 @@
+package resize_test
+
+import (
+	"image"
+	"online-photo-editor/internal/lib/api/resize"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizeImage_Sizes(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 100))
+
+	tests := []struct {
+		name   string
+		params resize.ResizeParams
+		want   image.Point
+	}{
+		{"exact", resize.ResizeParams{Width: 50, Height: 80}, image.Pt(50, 80)},
+		{"exact keeps aspect for a missing side", resize.ResizeParams{Width: 100}, image.Pt(100, 50)},
+		{"no upscale shrinks both sides alike", resize.ResizeParams{Width: 400, Height: 50, NoUpscale: true}, image.Pt(200, 25)},
+		{"no upscale leaves smaller sizes", resize.ResizeParams{Width: 100, Height: 80, NoUpscale: true}, image.Pt(100, 80)},
+		{"no upscale with one side", resize.ResizeParams{Height: 300, NoUpscale: true}, image.Pt(200, 100)},
+		{"fit", resize.ResizeParams{Mode: "fit", Width: 100, Height: 100}, image.Pt(100, 50)},
+		{"fill", resize.ResizeParams{Mode: "fill", Width: 100, Height: 100}, image.Pt(100, 100)},
+		{"fill upscales", resize.ResizeParams{Mode: "fill", Width: 400, Height: 400}, image.Pt(400, 400)},
+		{"fill without upscale", resize.ResizeParams{Mode: "fill", Width: 400, Height: 50, NoUpscale: true}, image.Pt(200, 50)},
+		{"scale", resize.ResizeParams{Mode: "scale", Percent: 150}, image.Pt(300, 150)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.params.ResizeImage(img)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.Bounds().Size())
+		})
+	}
+}
+
+func TestResizeImage_OutputLimit(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2000, 10))
+
+	for _, params := range []resize.ResizeParams{
+		{Mode: "scale", Percent: 1000},
+		{Height: 100},
+	} {
+		_, err := params.ResizeImage(img)
+		assert.ErrorContains(t, err, "exceeds 8000 pixels per side", "%+v", params)
+	}
+
+	// Fill crops before scaling, so a wide strip only scales up the part
+	// that ends up in the output.
+	params := resize.ResizeParams{Mode: "fill", Width: 500, Height: 500}
+	out, err := params.ResizeImage(img)
+	assert.NoError(t, err)
+	assert.Equal(t, image.Pt(500, 500), out.Bounds().Size())
+}
@@ -2,8 +2,10 @@ package response
 
 import (
 	"fmt"
+	"image"
 	"log/slog"
 	"net/http"
+	"online-photo-editor/internal/lib/format"
 	"online-photo-editor/internal/lib/logger/sl"
 	"strings"
 
@@ -16,17 +18,46 @@ type Response struct {
 	Error  string `json:"error,omitempty"`
 }
 
+type Rectangle struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
 const (
 	StatusOK    = "OK"
 	StatusError = "Error"
 )
 
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	_ = v.RegisterValidation("image_format", func(fl validator.FieldLevel) bool {
+		f, ok := format.Lookup(fl.Field().String())
+		return ok && f.CanEncode()
+	})
+
+	return v
+}
+
 func OK() Response {
 	return Response{
 		Status: StatusOK,
 	}
 }
 
+func Rect(r image.Rectangle) *Rectangle {
+	return &Rectangle{
+		X:      r.Min.X,
+		Y:      r.Min.Y,
+		Width:  r.Dx(),
+		Height: r.Dy(),
+	}
+}
+
 func Error(msg string) Response {
 	return Response{
 		Status: StatusError,
@@ -47,6 +78,8 @@ func ValidationError(errs validator.ValidationErrors) Response {
 			errMsgs = append(errMsgs, fmt.Sprintf("field %s is less than min value", err.Field()))
 		case "lowercase":
 			errMsgs = append(errMsgs, fmt.Sprintf("field %s is lis not lowercase", err.Field()))
+		case "image_format":
+			errMsgs = append(errMsgs, fmt.Sprintf("field %s must be one of the supported formats: %s", err.Field(), strings.Join(format.Encodable(), ", ")))
 		case "oneof":
 			errMsgs = append(errMsgs, fmt.Sprintf("field %s must be one of the allowed values", err.Field()))
 		default:
@@ -61,7 +94,7 @@ func ValidationError(errs validator.ValidationErrors) Response {
 }
 
 func Validation(log *slog.Logger, w http.ResponseWriter, r *http.Request, s interface{}, errStatus int) bool {
-	if err := validator.New().Struct(s); err != nil {
+	if err := validate.Struct(s); err != nil {
 		validateErr := err.(validator.ValidationErrors)
 
 		log.Error("invalid request", sl.Err(err))
@@ -0,0 +1,52 @@
+package responsive
+
+import (
+	"fmt"
+	"image"
+	"online-photo-editor/internal/lib/api/resize"
+	"slices"
+	"strings"
+)
+
+type ResponsiveParams struct {
+	Widths     []int    `json:"widths" validate:"required,min=1,max=16,dive,min=1,max=8000"`
+	Formats    []string `json:"formats" validate:"omitempty,max=4,dive,max=10,image_format"`
+	Background string   `json:"background" validate:"omitempty,max=20"`
+}
+
+// ResizeImages resizes img to every requested width keeping its aspect ratio.
+func (params *ResponsiveParams) ResizeImages(img image.Image) (map[int]image.Image, error) {
+	images := make(map[int]image.Image, len(params.Widths))
+
+	for _, width := range params.Widths {
+		if _, ok := images[width]; ok {
+			continue
+		}
+
+		resizeParams := resize.ResizeParams{Width: width}
+		resized, err := resizeParams.ResizeImage(img)
+		if err != nil {
+			return nil, err
+		}
+
+		images[width] = resized
+	}
+
+	return images, nil
+}
+
+// Srcset builds an HTML srcset attribute value from width to URL pairs.
+func Srcset(urls map[int]string) string {
+	widths := make([]int, 0, len(urls))
+	for width := range urls {
+		widths = append(widths, width)
+	}
+	slices.Sort(widths)
+
+	candidates := make([]string, 0, len(widths))
+	for _, width := range widths {
+		candidates = append(candidates, fmt.Sprintf("%s %dw", urls[width], width))
+	}
+
+	return strings.Join(candidates, ", ")
+}
@@ -0,0 +1,64 @@
+package selection
+
+import (
+	"fmt"
+	"image"
+	"online-photo-editor/internal/lib/geometry"
+	"online-photo-editor/internal/lib/mask"
+)
+
+const (
+	MethodMagicWand  = "magic_wand"
+	MethodColorRange = "color_range"
+)
+
+type SelectionParams struct {
+	Method     string           `json:"method" validate:"omitempty,oneof=magic_wand color_range"`
+	Point      *geometry.Point  `json:"point"`
+	Tolerance  float64          `json:"tolerance" validate:"min=0,max=100"`
+	Contiguous *bool            `json:"contiguous"`
+	ColorRange *mask.ColorRange `json:"color_range"`
+	Feather    float64          `json:"feather" validate:"min=0,max=100"`
+	Invert     bool             `json:"invert"`
+}
+
+// SelectImage builds a grayscale mask of img in which white marks the
+// selected pixels. The magic wand (default) selects pixels whose color is
+// within Tolerance percent of the color at Point, contiguous with it unless
+// Contiguous is false. The color range method selects pixels by hue,
+// saturation and value.
+func (params *SelectionParams) SelectImage(img image.Image) (*image.Gray, error) {
+	const op = "api.selection.SelectImage"
+
+	var m *image.Alpha
+	switch params.Method {
+	case "", MethodMagicWand:
+		if params.Point == nil {
+			return nil, fmt.Errorf("%s: point is required for the magic wand", op)
+		}
+		seed := image.Pt(int(params.Point.X), int(params.Point.Y))
+		if !seed.In(image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy())) {
+			return nil, fmt.Errorf("%s: point %v is outside the image", op, seed)
+		}
+		contiguous := params.Contiguous == nil || *params.Contiguous
+		m = mask.MagicWand(img, seed, params.Tolerance/100*0xff, contiguous)
+	case MethodColorRange:
+		if params.ColorRange == nil {
+			return nil, fmt.Errorf("%s: color_range is required for the color range method", op)
+		}
+		m = params.ColorRange.Select(img)
+	default:
+		return nil, fmt.Errorf("%s: unknown method %q", op, params.Method)
+	}
+
+	if params.Feather > 0 {
+		m = mask.Feather(m, params.Feather)
+	}
+	if params.Invert {
+		for i, a := range m.Pix {
+			m.Pix[i] = 255 - a
+		}
+	}
+
+	return &image.Gray{Pix: m.Pix, Stride: m.Stride, Rect: m.Rect}, nil
+}
@@ -0,0 +1,36 @@
+package sepia
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type SepiaParams struct {
+	Strength float64 `json:"strength" validate:"min=0,max=100"`
+}
+
+// SepiaImage applies the classic sepia tone matrix and mixes the result with
+// the original by Strength percent. A strength of 0 is treated as 100.
+func (params *SepiaParams) SepiaImage(img image.Image) (image.Image, error) {
+	strength := params.Strength / 100
+	if strength == 0 {
+		strength = 1
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+		sr := 0.393*r + 0.769*g + 0.189*b
+		sg := 0.349*r + 0.686*g + 0.168*b
+		sb := 0.272*r + 0.534*g + 0.131*b
+
+		return color.NRGBA{
+			R: colors.Clamp8(r + (sr-r)*strength),
+			G: colors.Clamp8(g + (sg-g)*strength),
+			B: colors.Clamp8(b + (sb-b)*strength),
+			A: c.A,
+		}
+	}), nil
+}
@@ -0,0 +1,219 @@
+package smartcrop
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"online-photo-editor/internal/lib/aspect"
+
+	"github.com/disintegration/imaging"
+)
+
+// analysisSize is the longest side of the downscaled copy the interest map is
+// computed on.
+const analysisSize = 256
+
+const (
+	edgeWeight       = 1.0
+	saturationWeight = 0.5
+	skinWeight       = 1.5
+	entropyWeight    = 0.5
+)
+
+type SmartCropParams struct {
+	Width  int    `json:"width" validate:"min=0,max=8000"`
+	Height int    `json:"height" validate:"min=0,max=8000"`
+	Aspect string `json:"aspect" validate:"omitempty,max=20"`
+}
+
+func (params *SmartCropParams) ratio() (float64, error) {
+	const op = "api.smartcrop.ratio"
+
+	if params.Width > 0 && params.Height > 0 {
+		return float64(params.Width) / float64(params.Height), nil
+	}
+
+	if params.Aspect == "" {
+		return 0, fmt.Errorf("%s: either width and height or aspect must be set", op)
+	}
+
+	return aspect.Parse(params.Aspect)
+}
+
+// SmartCropImage crops img to the window with the highest interest score and
+// returns the chosen rectangle. When width and height are set the crop is
+// resized to exactly that size.
+func (params *SmartCropParams) SmartCropImage(img image.Image) (image.Image, image.Rectangle, error) {
+	ratio, err := params.ratio()
+	if err != nil {
+		return nil, image.Rectangle{}, err
+	}
+
+	rect := FindCrop(img, ratio)
+	cropped := imaging.Crop(img, rect)
+
+	if params.Width > 0 && params.Height > 0 {
+		return imaging.Resize(cropped, params.Width, params.Height, imaging.Lanczos), rect, nil
+	}
+
+	return cropped, rect, nil
+}
+
+// FindCrop returns the largest window with the given aspect ratio that
+// maximizes the interest score of img.
+func FindCrop(img image.Image, ratio float64) image.Rectangle {
+	b := img.Bounds()
+	cropW, cropH := aspect.Fit(b.Dx(), b.Dy(), ratio)
+	if cropW == b.Dx() && cropH == b.Dy() {
+		return b
+	}
+
+	small := imaging.Fit(img, analysisSize, analysisSize, imaging.Box)
+	sw, sh := small.Bounds().Dx(), small.Bounds().Dy()
+	scale := float64(sw) / float64(b.Dx())
+
+	table := summedArea(interestMap(small), sw, sh)
+
+	winW := max(1, min(sw, int(math.Round(float64(cropW)*scale))))
+	winH := max(1, min(sh, int(math.Round(float64(cropH)*scale))))
+
+	// Ties, e.g. on flat images, go to the window closest to the center.
+	centerX, centerY := (sw-winW)/2, (sh-winH)/2
+	distance := func(x, y int) int {
+		return (x-centerX)*(x-centerX) + (y-centerY)*(y-centerY)
+	}
+
+	bestX, bestY, best := centerX, centerY, math.Inf(-1)
+	for y := 0; y+winH <= sh; y++ {
+		for x := 0; x+winW <= sw; x++ {
+			score := table.sum(x, y, x+winW, y+winH)
+			if score > best+1e-9 || (score > best-1e-9 && distance(x, y) < distance(bestX, bestY)) {
+				bestX, bestY, best = x, y, score
+			}
+		}
+	}
+
+	x := min(b.Dx()-cropW, int(math.Round(float64(bestX)/scale)))
+	y := min(b.Dy()-cropH, int(math.Round(float64(bestY)/scale)))
+
+	return image.Rect(b.Min.X+x, b.Min.Y+y, b.Min.X+x+cropW, b.Min.Y+y+cropH)
+}
+
+// interestMap scores every pixel by edge energy, saturation, skin tone
+// likelihood and local entropy.
+func interestMap(img *image.NRGBA) []float64 {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	luma := make([]float64, w*h)
+	scores := make([]float64, w*h)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			i := y*img.Stride + x*4
+			r, g, b := float64(img.Pix[i])/255, float64(img.Pix[i+1])/255, float64(img.Pix[i+2])/255
+			a := float64(img.Pix[i+3]) / 255
+			luma[y*w+x] = (0.299*r + 0.587*g + 0.114*b) * a
+			scores[y*w+x] = (saturationWeight*saturation(r, g, b) + skinWeight*skin(r, g, b)) * a
+		}
+	}
+
+	at := func(x, y int) float64 {
+		x = min(max(x, 0), w-1)
+		y = min(max(y, 0), h-1)
+		return luma[y*w+x]
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			gx := at(x+1, y-1) + 2*at(x+1, y) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x-1, y) - at(x-1, y+1)
+			gy := at(x-1, y+1) + 2*at(x, y+1) + at(x+1, y+1) - at(x-1, y-1) - 2*at(x, y-1) - at(x+1, y-1)
+			scores[y*w+x] += edgeWeight * math.Min(1, math.Hypot(gx, gy)/4)
+		}
+	}
+
+	const cell = 8
+	for cy := 0; cy < h; cy += cell {
+		for cx := 0; cx < w; cx += cell {
+			var hist [16]int
+			n := 0
+			for y := cy; y < min(cy+cell, h); y++ {
+				for x := cx; x < min(cx+cell, w); x++ {
+					hist[min(15, int(luma[y*w+x]*16))]++
+					n++
+				}
+			}
+
+			entropy := 0.0
+			for _, c := range hist {
+				if c > 0 {
+					p := float64(c) / float64(n)
+					entropy -= p * math.Log2(p)
+				}
+			}
+			entropy /= 4
+
+			for y := cy; y < min(cy+cell, h); y++ {
+				for x := cx; x < min(cx+cell, w); x++ {
+					scores[y*w+x] += entropyWeight * entropy
+				}
+			}
+		}
+	}
+
+	return scores
+}
+
+func saturation(r, g, b float64) float64 {
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	if hi == 0 {
+		return 0
+	}
+
+	l := (hi + lo) / 2
+	if l < 0.05 || l > 0.95 {
+		return 0
+	}
+
+	return (hi - lo) / hi
+}
+
+// skin returns how close the chromaticity of a color is to typical skin
+// tones, ignoring very dark and very bright pixels.
+func skin(r, g, b float64) float64 {
+	l := 0.299*r + 0.587*g + 0.114*b
+	if l < 0.2 || l > 0.95 {
+		return 0
+	}
+
+	mag := math.Sqrt(r*r + g*g + b*b)
+	if mag == 0 {
+		return 0
+	}
+
+	dr, dg, db := r/mag-0.78, g/mag-0.57, b/mag-0.44
+	d := math.Sqrt(dr*dr + dg*dg + db*db)
+
+	return math.Max(0, 1-d*5)
+}
+
+type areaTable struct {
+	w    int
+	sums []float64
+}
+
+func summedArea(values []float64, w, h int) areaTable {
+	t := areaTable{w: w + 1, sums: make([]float64, (w+1)*(h+1))}
+	for y := 0; y < h; y++ {
+		row := 0.0
+		for x := 0; x < w; x++ {
+			row += values[y*w+x]
+			t.sums[(y+1)*t.w+x+1] = t.sums[y*t.w+x+1] + row
+		}
+	}
+
+	return t
+}
+
+func (t areaTable) sum(x0, y0, x1, y1 int) float64 {
+	return t.sums[y1*t.w+x1] - t.sums[y0*t.w+x1] - t.sums[y1*t.w+x0] + t.sums[y0*t.w+x0]
+}
@@ -0,0 +1,122 @@
+package smartcrop_test
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math/rand"
+	"online-photo-editor/internal/lib/api/smartcrop"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func canvas(w, h int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(img, img.Rect, image.NewUniform(c), image.Point{}, draw.Src)
+
+	return img
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Src)
+}
+
+// checkerboard draws a fine black and white pattern, the most detailed area
+// an image can have.
+func checkerboard(img *image.NRGBA, r image.Rectangle) {
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			c := color.NRGBA{A: 255}
+			if (x/4+y/4)%2 == 0 {
+				c = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+}
+
+func TestFindCrop_Detail(t *testing.T) {
+	img := canvas(600, 300, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	detail := image.Rect(460, 100, 560, 200)
+	checkerboard(img, detail)
+
+	rect := smartcrop.FindCrop(img, 1)
+
+	assert.Equal(t, image.Pt(300, 300), rect.Size())
+	assert.True(t, detail.In(rect), "crop %v misses the detail at %v", rect, detail)
+}
+
+func TestFindCrop_Skin(t *testing.T) {
+	// Two patches with the same luma on a darker background, so they have
+	// the same edges; only the lower one has a skin tone.
+	img := canvas(400, 800, color.NRGBA{R: 77, G: 77, B: 77, A: 255})
+	gray := image.Rect(100, 50, 300, 200)
+	face := image.Rect(100, 600, 300, 750)
+	fill(img, gray, color.NRGBA{R: 184, G: 184, B: 184, A: 255})
+	fill(img, face, color.NRGBA{R: 224, G: 172, B: 138, A: 255})
+
+	rect := smartcrop.FindCrop(img, 16.0/9)
+
+	assert.Equal(t, image.Pt(400, 225), rect.Size())
+	assert.True(t, face.In(rect), "crop %v misses the skin tone at %v", rect, face)
+}
+
+func TestFindCrop_FlatImageIsCentered(t *testing.T) {
+	img := canvas(500, 200, color.White)
+
+	rect := smartcrop.FindCrop(img, 1)
+
+	assert.Equal(t, image.Rect(150, 0, 350, 200), rect)
+}
+
+func TestFindCrop_AspectRatio(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	img := image.NewNRGBA(image.Rect(0, 0, 640, 480))
+	rng.Read(img.Pix)
+	sub := img.SubImage(image.Rect(20, 10, 620, 410))
+
+	tests := []struct {
+		ratio float64
+		want  image.Point
+	}{
+		{1, image.Pt(400, 400)},
+		{16.0 / 9, image.Pt(600, 338)},
+		{9.0 / 16, image.Pt(225, 400)},
+		{3.0 / 2, image.Pt(600, 400)},
+	}
+
+	for _, tt := range tests {
+		rect := smartcrop.FindCrop(sub, tt.ratio)
+		assert.Equal(t, tt.want, rect.Size(), "ratio %v", tt.ratio)
+		assert.True(t, rect.In(sub.Bounds()), "ratio %v: crop %v is outside %v", tt.ratio, rect, sub.Bounds())
+	}
+}
+
+func TestSmartCropImage(t *testing.T) {
+	img := canvas(600, 300, color.NRGBA{R: 128, G: 128, B: 128, A: 255})
+	detail := image.Rect(20, 100, 120, 200)
+	checkerboard(img, detail)
+
+	params := smartcrop.SmartCropParams{Aspect: "1:1"}
+	out, rect, err := params.SmartCropImage(img)
+	require.NoError(t, err)
+	assert.True(t, detail.In(rect), "crop %v misses the detail at %v", rect, detail)
+	assert.Equal(t, image.Pt(300, 300), out.Bounds().Size())
+
+	// An exact size takes precedence over the aspect ratio.
+	params = smartcrop.SmartCropParams{Width: 100, Height: 50, Aspect: "1:1"}
+	out, rect, err = params.SmartCropImage(img)
+	require.NoError(t, err)
+	assert.Equal(t, image.Pt(600, 300), rect.Size())
+	assert.Equal(t, image.Pt(100, 50), out.Bounds().Size())
+
+	params = smartcrop.SmartCropParams{}
+	_, _, err = params.SmartCropImage(img)
+	assert.Error(t, err)
+
+	params = smartcrop.SmartCropParams{Aspect: "wide"}
+	_, _, err = params.SmartCropImage(img)
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package temperature
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type TemperatureParams struct {
+	Temperature float64 `json:"temperature" validate:"min=-100,max=100"`
+	Tint        float64 `json:"tint" validate:"min=-100,max=100"`
+}
+
+func (params *TemperatureParams) validate() error {
+	const op = "api.temperature.validate"
+
+	if params.Temperature == 0 && params.Tint == 0 {
+		return fmt.Errorf("%s: temperature or tint must be set", op)
+	}
+
+	return nil
+}
+
+// TemperatureImage warms up (positive temperature) or cools down the image
+// and shifts it towards magenta (positive tint) or green. The channels are
+// scaled in linear light and the overall luminance is kept.
+func (params *TemperatureParams) TemperatureImage(img image.Image) (image.Image, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	t, tint := params.Temperature/100, params.Tint/100
+	r, g, b := 1+0.3*t, 1-0.3*tint, 1-0.3*t
+
+	// Rec. 709 weights, the luminance of linear sRGB.
+	y := 0.2126*r + 0.7152*g + 0.0722*b
+
+	return applyGains(img, r/y, g/y, b/y), nil
+}
+
+func applyGains(img image.Image, r, g, b float64) *image.NRGBA {
+	var lut [3][256]uint8
+	for i, gain := range []float64{r, g, b} {
+		for v := range lut[i] {
+			lut[i][v] = colors.ToSRGB(colors.ToLinear(uint8(v)) * gain)
+		}
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[0][c.R], G: lut[1][c.G], B: lut[2][c.B], A: c.A}
+	})
+}
@@ -0,0 +1,52 @@
+package threshold
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+	"online-photo-editor/internal/lib/histogram"
+
+	"github.com/disintegration/imaging"
+)
+
+const otsuMethod = "otsu"
+
+type ThresholdParams struct {
+	Method    string `json:"method" validate:"omitempty,oneof=fixed otsu"`
+	Threshold *int   `json:"threshold" validate:"omitempty,min=0,max=255"`
+}
+
+// ThresholdImage turns the image into black and white by comparing the luma
+// of every pixel with the threshold, 128 by default. The otsu method picks
+// the threshold from the histogram of the image instead.
+func (params *ThresholdParams) ThresholdImage(img image.Image) (image.Image, error) {
+	src := imaging.Clone(img)
+
+	luma := func(r, g, b uint8) uint8 {
+		return colors.Clamp8(colors.Luma(float64(r), float64(g), float64(b)))
+	}
+
+	// Pixels at or above limit become white.
+	limit := 128
+	if params.Threshold != nil {
+		limit = *params.Threshold
+	}
+	if params.Method == otsuMethod {
+		var hist histogram.Histogram
+		for i := 0; i < len(src.Pix); i += 4 {
+			if src.Pix[i+3] != 0 {
+				hist[luma(src.Pix[i], src.Pix[i+1], src.Pix[i+2])]++
+			}
+		}
+		limit = int(hist.Otsu()) + 1
+	}
+
+	return imaging.AdjustFunc(src, func(c color.NRGBA) color.NRGBA {
+		var v uint8
+		if int(luma(c.R, c.G, c.B)) >= limit {
+			v = 255
+		}
+
+		return color.NRGBA{R: v, G: v, B: v, A: c.A}
+	}), nil
+}
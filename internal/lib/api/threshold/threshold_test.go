@@ -0,0 +1,81 @@
+package threshold_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/threshold"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	black = color.NRGBA{A: 255}
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+func grays(values ...uint8) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, len(values), 1))
+	for x, v := range values {
+		img.SetNRGBA(x, 0, color.NRGBA{R: v, G: v, B: v, A: 255})
+	}
+
+	return img
+}
+
+func row(img image.Image) []color.NRGBA {
+	var out []color.NRGBA
+	for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+		out = append(out, color.NRGBAModel.Convert(img.At(x, 0)).(color.NRGBA))
+	}
+
+	return out
+}
+
+func TestThresholdImage_Fixed(t *testing.T) {
+	limit := 200
+
+	tests := []struct {
+		name   string
+		params threshold.ThresholdParams
+		want   []color.NRGBA
+	}{
+		{"default", threshold.ThresholdParams{}, []color.NRGBA{black, black, white, white}},
+		{"custom", threshold.ThresholdParams{Method: "fixed", Threshold: &limit}, []color.NRGBA{black, black, black, white}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := tt.params.ThresholdImage(grays(0, 127, 128, 200))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, row(out))
+		})
+	}
+}
+
+func TestThresholdImage_UsesLuma(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	// Pure green is bright, pure blue is dark.
+	img.SetNRGBA(0, 0, color.NRGBA{G: 255, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{B: 255, A: 100})
+
+	out, err := (&threshold.ThresholdParams{}).ThresholdImage(img)
+	require.NoError(t, err)
+	assert.Equal(t, []color.NRGBA{white, {A: 100}}, row(out))
+}
+
+func TestThresholdImage_Otsu(t *testing.T) {
+	// Both groups are below the default threshold; otsu separates them.
+	img := grays(90, 92, 95, 90, 115, 118, 120, 115)
+
+	out, err := (&threshold.ThresholdParams{}).ThresholdImage(img)
+	require.NoError(t, err)
+	for _, c := range row(out) {
+		assert.Equal(t, black, c)
+	}
+
+	out, err = (&threshold.ThresholdParams{Method: "otsu"}).ThresholdImage(img)
+	require.NoError(t, err)
+	assert.Equal(t, []color.NRGBA{black, black, black, black, white, white, white, white}, row(out))
+}
@@ -0,0 +1,49 @@
+package tint
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type TintParams struct {
+	Color  string  `json:"color" validate:"required,max=20"`
+	Amount float64 `json:"amount" validate:"min=0,max=100"`
+}
+
+// TintImage casts the color over the image while keeping its own colors and
+// brightness. An amount of 0 is treated as 100.
+func (params *TintParams) TintImage(img image.Image) (image.Image, error) {
+	tint, err := colors.Parse(params.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := params.Amount / 100
+	if amount == 0 {
+		amount = 1
+	}
+
+	// The gains keep the luma of a gray pixel unchanged.
+	luma := colors.Luma(float64(tint.R), float64(tint.G), float64(tint.B))
+	if luma == 0 {
+		luma = 1
+	}
+	gain := [3]float64{float64(tint.R) / luma, float64(tint.G) / luma, float64(tint.B) / luma}
+	for i := range gain {
+		gain[i] = 1 + (gain[i]-1)*amount
+	}
+
+	var lut [3][256]uint8
+	for i := range lut {
+		for v := range lut[i] {
+			lut[i][v] = colors.Clamp8(float64(v) * gain[i])
+		}
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[0][c.R], G: lut[1][c.G], B: lut[2][c.B], A: c.A}
+	}), nil
+}
@@ -0,0 +1,108 @@
+package trim
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	colorMode       = "color"
+	transparentMode = "transparent"
+)
+
+type TrimParams struct {
+	Mode      string  `json:"mode" validate:"omitempty,oneof=corner color transparent"`
+	Color     string  `json:"color" validate:"omitempty,max=20"`
+	Tolerance float64 `json:"tolerance" validate:"min=0,max=100"`
+}
+
+// TrimImage removes uniform borders and returns the remaining rectangle. By
+// default the border color is taken from the top-left corner. An image that
+// is uniform as a whole is returned unchanged.
+func (params *TrimParams) TrimImage(img image.Image) (image.Image, image.Rectangle, error) {
+	b := img.Bounds()
+	limit := params.Tolerance / 100 * 0xff
+
+	var border func(c color.NRGBA) bool
+	switch params.Mode {
+	case transparentMode:
+		border = func(c color.NRGBA) bool {
+			return float64(c.A) <= limit
+		}
+	default:
+		ref := color.NRGBAModel.Convert(img.At(b.Min.X, b.Min.Y)).(color.NRGBA)
+		if params.Mode == colorMode {
+			c, err := colors.Parse(params.Color)
+			if err != nil {
+				return nil, image.Rectangle{}, err
+			}
+			ref = c
+		}
+		border = func(c color.NRGBA) bool {
+			return distance(c, ref) <= limit
+		}
+	}
+
+	src := imaging.Clone(img)
+	at := func(x, y int) color.NRGBA {
+		i := (y-b.Min.Y)*src.Stride + (x-b.Min.X)*4
+		return color.NRGBA{R: src.Pix[i], G: src.Pix[i+1], B: src.Pix[i+2], A: src.Pix[i+3]}
+	}
+
+	rowIsBorder := func(y, x0, x1 int) bool {
+		for x := x0; x < x1; x++ {
+			if !border(at(x, y)) {
+				return false
+			}
+		}
+		return true
+	}
+	colIsBorder := func(x, y0, y1 int) bool {
+		for y := y0; y < y1; y++ {
+			if !border(at(x, y)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	rect := b
+	for rect.Min.Y < rect.Max.Y && rowIsBorder(rect.Min.Y, rect.Min.X, rect.Max.X) {
+		rect.Min.Y++
+	}
+	if rect.Empty() {
+		return img, b, nil
+	}
+	for rowIsBorder(rect.Max.Y-1, rect.Min.X, rect.Max.X) {
+		rect.Max.Y--
+	}
+	for colIsBorder(rect.Min.X, rect.Min.Y, rect.Max.Y) {
+		rect.Min.X++
+	}
+	for colIsBorder(rect.Max.X-1, rect.Min.Y, rect.Max.Y) {
+		rect.Max.X--
+	}
+
+	return imaging.Crop(img, rect), rect, nil
+}
+
+// distance returns the largest per-channel difference between two colors.
+func distance(a, b color.NRGBA) float64 {
+	d := 0
+	for _, pair := range [][2]uint8{{a.R, b.R}, {a.G, b.G}, {a.B, b.B}, {a.A, b.A}} {
+		d = max(d, abs(int(pair[0])-int(pair[1])))
+	}
+
+	return float64(d)
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+
+	return v
+}
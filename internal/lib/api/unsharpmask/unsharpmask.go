@@ -0,0 +1,60 @@
+package unsharpmask
+
+import (
+	"image"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type UnsharpMaskParams struct {
+	Radius        float64 `json:"radius" validate:"required,min=0.1,max=100"`
+	Amount        float64 `json:"amount" validate:"min=0,max=500"`
+	Threshold     int     `json:"threshold" validate:"min=0,max=255"`
+	LuminanceOnly bool    `json:"luminance_only"`
+}
+
+// UnsharpMaskImage sharpens the image by adding Amount percent (default 100)
+// of the difference between the image and a Gaussian blur of Radius. Pixels
+// that differ from the blur by less than Threshold are left alone, which
+// keeps flat areas and noise from being sharpened. With LuminanceOnly the
+// same correction is added to all channels, so colors do not fringe.
+func (params *UnsharpMaskParams) UnsharpMaskImage(img image.Image) (image.Image, error) {
+	amount := params.Amount / 100
+	if amount == 0 {
+		amount = 1
+	}
+	threshold := float64(params.Threshold)
+
+	src := imaging.Clone(img)
+	blurred := imaging.Blur(src, params.Radius)
+
+	sharpen := func(v, b float64) float64 {
+		if math.Abs(v-b) < threshold {
+			return 0
+		}
+		return (v - b) * amount
+	}
+
+	dst := image.NewNRGBA(src.Bounds())
+	for i := 0; i < len(src.Pix); i += 4 {
+		o, b := src.Pix[i:i+3], blurred.Pix[i:i+3]
+
+		if params.LuminanceOnly {
+			y := colors.Luma(float64(o[0]), float64(o[1]), float64(o[2]))
+			yb := colors.Luma(float64(b[0]), float64(b[1]), float64(b[2]))
+			delta := sharpen(y, yb)
+			for c := 0; c < 3; c++ {
+				dst.Pix[i+c] = colors.Clamp8(float64(o[c]) + delta)
+			}
+		} else {
+			for c := 0; c < 3; c++ {
+				dst.Pix[i+c] = colors.Clamp8(float64(o[c]) + sharpen(float64(o[c]), float64(b[c])))
+			}
+		}
+		dst.Pix[i+3] = src.Pix[i+3]
+	}
+
+	return dst, nil
+}
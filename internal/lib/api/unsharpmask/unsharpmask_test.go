@@ -0,0 +1,93 @@
+package unsharpmask_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/unsharpmask"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// step returns a 20 x 3 image whose left half is lo and right half is hi.
+func step(lo, hi color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 20, 3))
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 20; x++ {
+			c := lo
+			if x >= 10 {
+				c = hi
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func gray(v uint8) color.NRGBA {
+	return color.NRGBA{R: v, G: v, B: v, A: 255}
+}
+
+func at(img image.Image, x int) color.NRGBA {
+	return color.NRGBAModel.Convert(img.At(x, 1)).(color.NRGBA)
+}
+
+func TestUnsharpMaskImage_StepEdge(t *testing.T) {
+	src := step(gray(100), gray(150))
+
+	out, err := (&unsharpmask.UnsharpMaskParams{Radius: 1}).UnsharpMaskImage(src)
+	require.NoError(t, err)
+
+	// Away from the edge the image is flat and stays as it is.
+	for _, x := range []int{0, 1, 2, 3, 16, 17, 18, 19} {
+		assert.Equal(t, src.NRGBAAt(x, 1), at(out, x), "pixel %d", x)
+	}
+
+	// Next to the edge the dark side gets darker and the bright side
+	// brighter, most of all right at the edge.
+	dark, bright := at(out, 9), at(out, 10)
+	assert.Less(t, dark.R, uint8(100))
+	assert.Greater(t, bright.R, uint8(150))
+	assert.Less(t, dark.R, at(out, 8).R)
+	assert.Greater(t, bright.R, at(out, 11).R)
+	assert.Equal(t, dark.R, dark.G)
+	assert.Equal(t, dark.R, dark.B)
+
+	// Twice the amount doubles the overshoot.
+	double, err := (&unsharpmask.UnsharpMaskParams{Radius: 1, Amount: 200}).UnsharpMaskImage(src)
+	require.NoError(t, err)
+	assert.InDelta(t, 2*(100-int(dark.R)), 100-int(at(double, 9).R), 1)
+	assert.InDelta(t, 2*(int(bright.R)-150), int(at(double, 10).R)-150, 1)
+}
+
+func TestUnsharpMaskImage_Threshold(t *testing.T) {
+	src := step(gray(50), gray(200))
+
+	out, err := (&unsharpmask.UnsharpMaskParams{Radius: 1, Threshold: 255}).UnsharpMaskImage(src)
+	require.NoError(t, err)
+	assert.Equal(t, src.Pix, out.(*image.NRGBA).Pix)
+
+	// A low threshold only protects pixels whose difference from the blur is
+	// small, so the edge itself is still sharpened.
+	out, err = (&unsharpmask.UnsharpMaskParams{Radius: 1, Threshold: 5}).UnsharpMaskImage(src)
+	require.NoError(t, err)
+	assert.Less(t, at(out, 9).R, uint8(50))
+}
+
+func TestUnsharpMaskImage_LuminanceOnly(t *testing.T) {
+	src := step(color.NRGBA{R: 120, G: 60, B: 90, A: 255}, color.NRGBA{R: 90, G: 160, B: 130, A: 200})
+
+	out, err := (&unsharpmask.UnsharpMaskParams{Radius: 1, LuminanceOnly: true}).UnsharpMaskImage(src)
+	require.NoError(t, err)
+
+	for x := 0; x < 20; x++ {
+		o, c := src.NRGBAAt(x, 1), at(out, x)
+		delta := int(c.R) - int(o.R)
+		assert.Equal(t, delta, int(c.G)-int(o.G), "pixel %d", x)
+		assert.Equal(t, delta, int(c.B)-int(o.B), "pixel %d", x)
+		assert.Equal(t, o.A, c.A, "pixel %d", x)
+	}
+	assert.NotEqual(t, src.NRGBAAt(9, 1), at(out, 9))
+}
@@ -0,0 +1,37 @@
+package vibrance
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+type VibranceParams struct {
+	Percentage float64 `json:"percentage" validate:"required,min=-100,max=100"`
+}
+
+// VibranceImage changes the saturation of muted colors more than that of
+// already saturated ones, which keeps skin tones and vivid areas natural.
+func (params *VibranceParams) VibranceImage(img image.Image) (image.Image, error) {
+	amount := params.Percentage / 100
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		r, g, b := float64(c.R), float64(c.G), float64(c.B)
+		hi := math.Max(r, math.Max(g, b))
+		lo := math.Min(r, math.Min(g, b))
+		sat := (hi - lo) / 255
+
+		f := 1 + amount*(1-sat)
+		luma := colors.Luma(r, g, b)
+
+		return color.NRGBA{
+			R: colors.Clamp8(luma + (r-luma)*f),
+			G: colors.Clamp8(luma + (g-luma)*f),
+			B: colors.Clamp8(luma + (b-luma)*f),
+			A: c.A,
+		}
+	}), nil
+}
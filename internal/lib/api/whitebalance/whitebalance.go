@@ -0,0 +1,173 @@
+package whitebalance
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	whitePatchMode = "white_patch"
+	colorMode      = "color"
+	kelvinMode     = "kelvin"
+
+	// maxGain limits the correction of nearly empty channels.
+	maxGain = 8
+)
+
+type WhiteBalanceParams struct {
+	Mode   string `json:"mode" validate:"omitempty,oneof=gray_world white_patch color kelvin"`
+	Color  string `json:"color" validate:"omitempty,max=20"`
+	Kelvin int    `json:"kelvin" validate:"omitempty,min=1000,max=40000"`
+}
+
+// WhiteBalanceImage removes a color cast. gray_world (the default) assumes
+// the average of the scene is neutral, white_patch assumes its brightest
+// pixels are, color makes the given color neutral and kelvin corrects for a
+// light source of the given color temperature. Green is kept as is and red
+// and blue are scaled in linear light.
+func (params *WhiteBalanceParams) WhiteBalanceImage(img image.Image) (image.Image, error) {
+	const op = "api.whitebalance.WhiteBalanceImage"
+
+	var ref [3]float64
+
+	switch params.Mode {
+	case colorMode:
+		c, err := colors.Parse(params.Color)
+		if err != nil {
+			return nil, err
+		}
+		ref = [3]float64{colors.ToLinear(c.R), colors.ToLinear(c.G), colors.ToLinear(c.B)}
+	case kelvinMode:
+		if params.Kelvin == 0 {
+			return nil, fmt.Errorf("%s: kelvin must be set in kelvin mode", op)
+		}
+		light, d65 := blackbody(float64(params.Kelvin)), blackbody(6500)
+		for i := range ref {
+			ref[i] = light[i] / d65[i]
+		}
+	default:
+		ref = reference(imaging.Clone(img), params.Mode == whitePatchMode)
+	}
+
+	var gains [3]float64
+	for i := range gains {
+		gains[i] = 1
+		if ref[i] > 0 {
+			gains[i] = math.Min(ref[1]/ref[i], maxGain)
+		}
+	}
+
+	var lut [3][256]uint8
+	for i, gain := range gains {
+		for v := range lut[i] {
+			lut[i][v] = colors.ToSRGB(colors.ToLinear(uint8(v)) * gain)
+		}
+	}
+
+	return imaging.AdjustFunc(img, func(c color.NRGBA) color.NRGBA {
+		return color.NRGBA{R: lut[0][c.R], G: lut[1][c.G], B: lut[2][c.B], A: c.A}
+	}), nil
+}
+
+// reference returns the color, in linear light, that should become neutral:
+// the sum of all visible pixels or of the brightest percent of them. Only the
+// ratios between the channels matter.
+func reference(src *image.NRGBA, brightest bool) [3]float64 {
+	linear := func(i int) [3]float64 {
+		return [3]float64{colors.ToLinear(src.Pix[i]), colors.ToLinear(src.Pix[i+1]), colors.ToLinear(src.Pix[i+2])}
+	}
+	luma := func(c [3]float64) int {
+		return int(math.Round(255 * min(0.2126*c[0]+0.7152*c[1]+0.0722*c[2], 1)))
+	}
+
+	var sum [3]float64
+	add := func(c [3]float64) {
+		for i := range sum {
+			sum[i] += c[i]
+		}
+	}
+
+	if !brightest {
+		for i := 0; i < len(src.Pix); i += 4 {
+			if src.Pix[i+3] != 0 {
+				add(linear(i))
+			}
+		}
+		return sum
+	}
+
+	// A histogram of the luma gives the threshold of the brightest percent
+	// without sorting the pixels. Pixels at the threshold are taken in order
+	// until the percent is reached.
+	var hist [256]int
+	total := 0
+	for i := 0; i < len(src.Pix); i += 4 {
+		if src.Pix[i+3] != 0 {
+			hist[luma(linear(i))]++
+			total++
+		}
+	}
+	if total == 0 {
+		return sum
+	}
+
+	want := max(total/100, 1)
+	threshold, above := 255, 0
+	for threshold > 0 && above+hist[threshold] < want {
+		above += hist[threshold]
+		threshold--
+	}
+	atThreshold := want - above
+
+	for i := 0; i < len(src.Pix); i += 4 {
+		if src.Pix[i+3] == 0 {
+			continue
+		}
+		c := linear(i)
+		switch l := luma(c); {
+		case l > threshold:
+			add(c)
+		case l == threshold && atThreshold > 0:
+			add(c)
+			atThreshold--
+		}
+	}
+
+	return sum
+}
+
+// blackbody approximates the linear sRGB color of a black body radiator at
+// the given temperature in kelvin.
+func blackbody(kelvin float64) [3]float64 {
+	t := kelvin / 100
+
+	var r, g, b float64
+	if t <= 66 {
+		r = 255
+		g = 99.4708025861*math.Log(t) - 161.1195681661
+	} else {
+		r = 329.698727446 * math.Pow(t-60, -0.1332047592)
+		g = 288.1221695283 * math.Pow(t-60, -0.0755148492)
+	}
+
+	switch {
+	case t >= 66:
+		b = 255
+	case t <= 19:
+		b = 0
+	default:
+		b = 138.5177312231*math.Log(t-10) - 305.0447927307
+	}
+
+	var c [3]float64
+	for i, v := range []float64{r, g, b} {
+		c[i] = math.Max(colors.ToLinear(colors.Clamp8(v)), 1e-4)
+	}
+
+	return c
+}
@@ -0,0 +1,46 @@
+package whitebalance_test
+
+import (
+	"image"
+	"image/color"
+	"online-photo-editor/internal/lib/api/whitebalance"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhiteBalanceImage_GrayWorld(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for i := 0; i < len(img.Pix); i += 4 {
+		copy(img.Pix[i:i+4], []uint8{150, 120, 90, 255})
+	}
+	// Transparent pixels do not count.
+	img.SetNRGBA(0, 0, color.NRGBA{B: 255})
+
+	out, err := (&whitebalance.WhiteBalanceParams{}).WhiteBalanceImage(img)
+	assert.NoError(t, err)
+
+	c := out.(*image.NRGBA).NRGBAAt(5, 5)
+	assert.InDelta(t, 120, int(c.R), 1)
+	assert.Equal(t, uint8(120), c.G)
+	assert.InDelta(t, 120, int(c.B), 1)
+}
+
+func TestWhiteBalanceImage_WhitePatch(t *testing.T) {
+	// 199 dark neutral pixels and a single bright tinted one: only the
+	// brightest percent, the tinted pixel and the brightest dark pixel,
+	// sets the balance.
+	img := image.NewNRGBA(image.Rect(0, 0, 200, 1))
+	for x := 0; x < 199; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{R: 40, G: 40, B: 40, A: 255})
+	}
+	img.SetNRGBA(199, 0, color.NRGBA{R: 200, G: 230, B: 255, A: 255})
+
+	out, err := (&whitebalance.WhiteBalanceParams{Mode: "white_patch"}).WhiteBalanceImage(img)
+	assert.NoError(t, err)
+
+	c := out.(*image.NRGBA).NRGBAAt(199, 0)
+	assert.InDelta(t, int(c.G), int(c.R), 6)
+	assert.InDelta(t, int(c.G), int(c.B), 6)
+	assert.Equal(t, uint8(230), c.G)
+}
@@ -0,0 +1,48 @@
+package aspect
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Parse reads an aspect ratio given as "16:9", "16/9" or "1.777".
+func Parse(s string) (float64, error) {
+	const op = "lib.aspect.Parse"
+
+	s = strings.TrimSpace(s)
+	sep := strings.IndexAny(s, ":/")
+
+	var ratio float64
+	if sep == -1 {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: invalid aspect ratio %q", op, s)
+		}
+		ratio = v
+	} else {
+		w, errW := strconv.ParseFloat(strings.TrimSpace(s[:sep]), 64)
+		h, errH := strconv.ParseFloat(strings.TrimSpace(s[sep+1:]), 64)
+		if errW != nil || errH != nil || h == 0 {
+			return 0, fmt.Errorf("%s: invalid aspect ratio %q", op, s)
+		}
+		ratio = w / h
+	}
+
+	if ratio <= 0 || math.IsInf(ratio, 0) || math.IsNaN(ratio) {
+		return 0, fmt.Errorf("%s: invalid aspect ratio %q", op, s)
+	}
+
+	return ratio, nil
+}
+
+// Fit returns the largest width and height with the given ratio that fit
+// inside a w x h area.
+func Fit(w, h int, ratio float64) (int, int) {
+	if float64(w)/float64(h) > ratio {
+		return max(1, min(w, int(math.Round(float64(h)*ratio)))), h
+	}
+
+	return w, max(1, min(h, int(math.Round(float64(w)/ratio))))
+}
@@ -0,0 +1,57 @@
+package colors
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+var named = map[string]color.NRGBA{
+	"transparent": {},
+	"black":       {0, 0, 0, 255},
+	"white":       {255, 255, 255, 255},
+	"red":         {255, 0, 0, 255},
+	"green":       {0, 255, 0, 255},
+	"blue":        {0, 0, 255, 255},
+	"yellow":      {255, 255, 0, 255},
+	"cyan":        {0, 255, 255, 255},
+	"magenta":     {255, 0, 255, 255},
+	"gray":        {128, 128, 128, 255},
+}
+
+// Parse converts a color name or a hex string in #rgb, #rgba, #rrggbb or
+// #rrggbbaa form into a color.
+func Parse(s string) (color.NRGBA, error) {
+	const op = "lib.colors.Parse"
+
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := named[s]; ok {
+		return c, nil
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	switch len(hex) {
+	case 3, 4:
+		var expanded strings.Builder
+		for _, r := range hex {
+			expanded.WriteRune(r)
+			expanded.WriteRune(r)
+		}
+		hex = expanded.String()
+	case 6, 8:
+	default:
+		return color.NRGBA{}, fmt.Errorf("%s: invalid color %q", op, s)
+	}
+
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, fmt.Errorf("%s: invalid color %q", op, s)
+	}
+
+	return color.NRGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+}
@@ -0,0 +1,85 @@
+package colors
+
+import "math"
+
+// RGBToHSL converts color components in [0, 1] into hue in degrees
+// [0, 360), saturation and lightness in [0, 1].
+func RGBToHSL(r, g, b float64) (h, s, l float64) {
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	l = (hi + lo) / 2
+
+	d := hi - lo
+	if d == 0 {
+		return 0, 0, l
+	}
+
+	if l > 0.5 {
+		s = d / (2 - hi - lo)
+	} else {
+		s = d / (hi + lo)
+	}
+
+	switch hi {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+
+	return h * 60, s, l
+}
+
+// HSLToRGB is the inverse of RGBToHSL. The hue is wrapped into [0, 360).
+func HSLToRGB(h, s, l float64) (r, g, b float64) {
+	if s == 0 {
+		return l, l, l
+	}
+
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	h /= 360
+
+	var q float64
+	if l < 0.5 {
+		q = l * (1 + s)
+	} else {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+
+	return hueToRGB(p, q, h+1.0/3), hueToRGB(p, q, h), hueToRGB(p, q, h-1.0/3)
+}
+
+func hueToRGB(p, q, t float64) float64 {
+	if t < 0 {
+		t++
+	}
+	if t > 1 {
+		t--
+	}
+
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 0.5:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	default:
+		return p
+	}
+}
+
+// Luma returns the Rec. 601 luma of gamma-encoded components, the same
+// weights imaging.Grayscale uses.
+func Luma(r, g, b float64) float64 {
+	return 0.299*r + 0.587*g + 0.114*b
+}
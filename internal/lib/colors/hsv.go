@@ -0,0 +1,31 @@
+package colors
+
+import "math"
+
+// RGBToHSV converts color components in [0, 1] into hue in degrees
+// [0, 360), saturation and value in [0, 1].
+func RGBToHSV(r, g, b float64) (h, s, v float64) {
+	hi := math.Max(r, math.Max(g, b))
+	lo := math.Min(r, math.Min(g, b))
+	v = hi
+
+	d := hi - lo
+	if d == 0 {
+		return 0, 0, v
+	}
+	s = d / hi
+
+	switch hi {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+
+	return h * 60, s, v
+}
@@ -0,0 +1,43 @@
+package colors
+
+import "math"
+
+var toLinear [256]float64
+
+func init() {
+	for i := range toLinear {
+		v := float64(i) / 255
+		if v <= 0.04045 {
+			toLinear[i] = v / 12.92
+		} else {
+			toLinear[i] = math.Pow((v+0.055)/1.055, 2.4)
+		}
+	}
+}
+
+// ToLinear converts an 8-bit sRGB component into linear light in [0, 1].
+func ToLinear(v uint8) float64 {
+	return toLinear[v]
+}
+
+// ToSRGB converts linear light into an 8-bit sRGB component, clamping values
+// outside [0, 1].
+func ToSRGB(v float64) uint8 {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 255
+	case v <= 0.0031308:
+		v *= 12.92
+	default:
+		v = 1.055*math.Pow(v, 1/2.4) - 0.055
+	}
+
+	return uint8(math.Round(v * 255))
+}
+
+// Clamp8 rounds v and clamps it into the 8-bit range.
+func Clamp8(v float64) uint8 {
+	return uint8(math.Round(math.Min(math.Max(v, 0), 255)))
+}
@@ -0,0 +1,218 @@
+package convolution
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"online-photo-editor/internal/lib/parallel"
+)
+
+// Edge modes decide which value is used for pixels outside the image.
+const (
+	Extend = "extend"
+	Wrap   = "wrap"
+	Mirror = "mirror"
+	Zero   = "zero"
+)
+
+// MaxKernelSize is the largest supported width and height of a 2D kernel.
+const MaxKernelSize = 25
+
+// Plane is a single channel of an image with float values in [0, 255].
+type Plane struct {
+	W, H int
+	Pix  []float64
+}
+
+func NewPlane(w, h int) *Plane {
+	return &Plane{W: w, H: h, Pix: make([]float64, w*h)}
+}
+
+// Split returns the red, green, blue and alpha planes of img.
+func Split(img *image.NRGBA) [4]*Plane {
+	b := img.Bounds()
+	var planes [4]*Plane
+	for c := range planes {
+		planes[c] = NewPlane(b.Dx(), b.Dy())
+	}
+
+	for y := 0; y < b.Dy(); y++ {
+		row := img.Pix[y*img.Stride : y*img.Stride+b.Dx()*4]
+		for x := 0; x < b.Dx(); x++ {
+			for c := range planes {
+				planes[c].Pix[y*b.Dx()+x] = float64(row[x*4+c])
+			}
+		}
+	}
+
+	return planes
+}
+
+// Merge builds an image from red, green, blue and alpha planes of the same
+// size, rounding and clamping the values.
+func Merge(planes [4]*Plane) *image.NRGBA {
+	w, h := planes[0].W, planes[0].H
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for i := 0; i < w*h; i++ {
+		for c := range planes {
+			img.Pix[i*4+c] = uint8(math.Round(math.Min(math.Max(planes[c].Pix[i], 0), 255)))
+		}
+	}
+
+	return img
+}
+
+// Validate checks that kernel is square with an odd size up to
+// MaxKernelSize.
+func Validate(kernel [][]float64) error {
+	const op = "lib.convolution.Validate"
+
+	n := len(kernel)
+	if n == 0 || n%2 == 0 || n > MaxKernelSize {
+		return fmt.Errorf("%s: kernel size must be odd and at most %d", op, MaxKernelSize)
+	}
+	for _, row := range kernel {
+		if len(row) != n {
+			return fmt.Errorf("%s: kernel must be square", op)
+		}
+	}
+
+	return nil
+}
+
+// Convolve applies a square kernel with an odd size to the plane. The kernel
+// is used as given, without flipping.
+func (p *Plane) Convolve(kernel [][]float64, edge string) *Plane {
+	out := NewPlane(p.W, p.H)
+	r := len(kernel) / 2
+	xs, ys := indices(p.W, r, edge), indices(p.H, r, edge)
+
+	parallel.Rows(p.H, func(y int) {
+		row := out.Pix[y*p.W : (y+1)*p.W]
+		for ky, weights := range kernel {
+			sy := ys[y+ky]
+			if sy < 0 {
+				continue
+			}
+			src := p.Pix[sy*p.W : (sy+1)*p.W]
+			for kx, k := range weights {
+				if k == 0 {
+					continue
+				}
+				taps := xs[kx : kx+p.W]
+				for x, sx := range taps {
+					if sx >= 0 {
+						row[x] += k * src[sx]
+					}
+				}
+			}
+		}
+	})
+
+	return out
+}
+
+// Box averages every value with its neighbors in a square of 2*radius+1
+// values using running sums, so the cost does not depend on the radius.
+func (p *Plane) Box(radius int, edge string) *Plane {
+	return p.boxRows(radius, edge).transpose().boxRows(radius, edge).transpose()
+}
+
+func (p *Plane) boxRows(radius int, edge string) *Plane {
+	out := NewPlane(p.W, p.H)
+	xs := indices(p.W, radius, edge)
+	size := float64(2*radius + 1)
+
+	value := func(src []float64, i int) float64 {
+		if xs[i] < 0 {
+			return 0
+		}
+		return src[xs[i]]
+	}
+
+	parallel.Rows(p.H, func(y int) {
+		src := p.Pix[y*p.W : (y+1)*p.W]
+		row := out.Pix[y*p.W : (y+1)*p.W]
+
+		sum := 0.0
+		for i := 0; i < 2*radius+1; i++ {
+			sum += value(src, i)
+		}
+		for x := range row {
+			row[x] = sum / size
+			if x+1 < p.W {
+				sum += value(src, x+2*radius+1) - value(src, x)
+			}
+		}
+	})
+
+	return out
+}
+
+func (p *Plane) transpose() *Plane {
+	t := NewPlane(p.H, p.W)
+	for y := 0; y < p.H; y++ {
+		for x := 0; x < p.W; x++ {
+			t.Pix[x*t.W+y] = p.Pix[y*p.W+x]
+		}
+	}
+
+	return t
+}
+
+// Map replaces every value v with fn(v).
+func (p *Plane) Map(fn func(v float64) float64) *Plane {
+	for i, v := range p.Pix {
+		p.Pix[i] = fn(v)
+	}
+
+	return p
+}
+
+// indices maps the positions -r..size+r-1, shifted by r, to the position that
+// is read according to the edge mode, or -1 for a zero.
+func indices(size, r int, edge string) []int {
+	xs := make([]int, size+2*r)
+	for i := range xs {
+		v := i - r
+		switch {
+		case v >= 0 && v < size:
+		case edge == Zero:
+			v = -1
+		case edge == Wrap:
+			v = wrap(v, size)
+		case edge == Mirror:
+			v = mirror(v, size)
+		default:
+			v = min(max(v, 0), size-1)
+		}
+		xs[i] = v
+	}
+
+	return xs
+}
+
+func wrap(v, size int) int {
+	v %= size
+	if v < 0 {
+		v += size
+	}
+
+	return v
+}
+
+// mirror reflects v into [0, size) without repeating the edge pixel.
+func mirror(v, size int) int {
+	if size == 1 {
+		return 0
+	}
+
+	period := 2 * (size - 1)
+	v = wrap(v, period)
+	if v >= size {
+		v = period - v
+	}
+
+	return v
+}
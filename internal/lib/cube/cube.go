@@ -0,0 +1,236 @@
+package cube
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// MaxSize is the largest supported LUT_3D_SIZE, the largest size common
+// grading tools export; a file of that size fits the 32 MB upload limit.
+const MaxSize = 65
+
+// LUT is a 3D color lookup table read from a .cube file. Table holds
+// Size^3 output colors with red changing fastest.
+type LUT struct {
+	Title     string
+	Size      int
+	DomainMin [3]float64
+	DomainMax [3]float64
+	Table     [][3]float32
+}
+
+// Parse reads a .cube file in the Adobe/Resolve format.
+func Parse(r io.Reader) (*LUT, error) {
+	const op = "lib.cube.Parse"
+
+	lut := &LUT{DomainMax: [3]float64{1, 1, 1}}
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		// Comments may also trail a line, except in a quoted title.
+		if i := strings.IndexByte(text, '#'); i >= 0 && !strings.HasPrefix(text, "TITLE") {
+			text = strings.TrimSpace(text[:i])
+		}
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		switch keyword := fields[0]; keyword {
+		case "TITLE":
+			lut.Title = strings.Trim(strings.TrimSpace(strings.TrimPrefix(text, "TITLE")), `"`)
+		case "LUT_1D_SIZE":
+			return nil, fmt.Errorf("%s: 1D LUTs are not supported", op)
+		case "LUT_3D_SIZE":
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("%s: line %d: invalid LUT_3D_SIZE", op, line)
+			}
+			size, err := strconv.Atoi(fields[1])
+			if err != nil || size < 2 || size > MaxSize {
+				return nil, fmt.Errorf("%s: line %d: LUT_3D_SIZE must be between 2 and %d", op, line, MaxSize)
+			}
+			lut.Size = size
+			lut.Table = make([][3]float32, 0, size*size*size)
+		case "DOMAIN_MIN", "DOMAIN_MAX":
+			values, err := parseFloats(fields[1:], 3)
+			if err != nil {
+				return nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+			}
+			target := &lut.DomainMin
+			if keyword == "DOMAIN_MAX" {
+				target = &lut.DomainMax
+			}
+			copy(target[:], values)
+		case "LUT_3D_INPUT_RANGE":
+			values, err := parseFloats(fields[1:], 2)
+			if err != nil {
+				return nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+			}
+			lut.DomainMin = [3]float64{values[0], values[0], values[0]}
+			lut.DomainMax = [3]float64{values[1], values[1], values[1]}
+		default:
+			// Other keywords, such as LUT_1D_INPUT_RANGE or vendor
+			// extensions, do not affect the 3D table and are skipped.
+			if isKeyword(keyword) {
+				if len(lut.Table) > 0 {
+					return nil, fmt.Errorf("%s: line %d: keyword %s after table data", op, line, keyword)
+				}
+				continue
+			}
+			if lut.Size == 0 {
+				return nil, fmt.Errorf("%s: line %d: data before LUT_3D_SIZE", op, line)
+			}
+			values, err := parseFloats(fields, 3)
+			if err != nil {
+				return nil, fmt.Errorf("%s: line %d: %w", op, line, err)
+			}
+			if len(lut.Table) == cap(lut.Table) {
+				return nil, fmt.Errorf("%s: line %d: too many table entries", op, line)
+			}
+			lut.Table = append(lut.Table, [3]float32{float32(values[0]), float32(values[1]), float32(values[2])})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if lut.Size == 0 {
+		return nil, fmt.Errorf("%s: LUT_3D_SIZE is missing", op)
+	}
+	if len(lut.Table) != cap(lut.Table) {
+		return nil, fmt.Errorf("%s: expected %d table entries, got %d", op, cap(lut.Table), len(lut.Table))
+	}
+	for i := range lut.DomainMin {
+		if lut.DomainMax[i] <= lut.DomainMin[i] {
+			return nil, fmt.Errorf("%s: DOMAIN_MAX must be greater than DOMAIN_MIN", op)
+		}
+	}
+
+	return lut, nil
+}
+
+// isKeyword reports whether a field starts with a letter, which numbers in
+// table rows never do.
+func isKeyword(field string) bool {
+	c := field[0]
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func parseFloats(fields []string, n int) ([]float64, error) {
+	if len(fields) != n {
+		return nil, fmt.Errorf("expected %d values, got %d", n, len(fields))
+	}
+
+	values := make([]float64, n)
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("invalid number %q", f)
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+// grid maps a color in [0, 1] to table coordinates, clamped to the domain.
+func (l *LUT) grid(c [3]float64) [3]float64 {
+	var g [3]float64
+	for i := range c {
+		v := (c[i] - l.DomainMin[i]) / (l.DomainMax[i] - l.DomainMin[i])
+		g[i] = math.Min(math.Max(v, 0), 1) * float64(l.Size-1)
+	}
+
+	return g
+}
+
+func (l *LUT) at(r, g, b int) [3]float64 {
+	v := l.Table[(b*l.Size+g)*l.Size+r]
+	return [3]float64{float64(v[0]), float64(v[1]), float64(v[2])}
+}
+
+// cell returns the lower corner of the cell containing g, clamped so that
+// the upper corner stays inside the table, and the position within it.
+func (l *LUT) cell(g [3]float64) ([3]int, [3]float64) {
+	var base [3]int
+	var frac [3]float64
+	for i, v := range g {
+		base[i] = min(int(v), l.Size-2)
+		frac[i] = v - float64(base[i])
+	}
+
+	return base, frac
+}
+
+// Trilinear looks up c with trilinear interpolation between the eight
+// surrounding table entries.
+func (l *LUT) Trilinear(c [3]float64) [3]float64 {
+	base, f := l.cell(l.grid(c))
+	r, g, b := base[0], base[1], base[2]
+
+	var out [3]float64
+	c000, c100 := l.at(r, g, b), l.at(r+1, g, b)
+	c010, c110 := l.at(r, g+1, b), l.at(r+1, g+1, b)
+	c001, c101 := l.at(r, g, b+1), l.at(r+1, g, b+1)
+	c011, c111 := l.at(r, g+1, b+1), l.at(r+1, g+1, b+1)
+	for i := range out {
+		c00 := c000[i] + (c100[i]-c000[i])*f[0]
+		c10 := c010[i] + (c110[i]-c010[i])*f[0]
+		c01 := c001[i] + (c101[i]-c001[i])*f[0]
+		c11 := c011[i] + (c111[i]-c011[i])*f[0]
+		c0 := c00 + (c10-c00)*f[1]
+		c1 := c01 + (c11-c01)*f[1]
+		out[i] = c0 + (c1-c0)*f[2]
+	}
+
+	return out
+}
+
+// Tetrahedral looks up c with tetrahedral interpolation, which uses four of
+// the surrounding entries and preserves the neutral axis better.
+func (l *LUT) Tetrahedral(c [3]float64) [3]float64 {
+	base, f := l.cell(l.grid(c))
+	r, g, b := base[0], base[1], base[2]
+	fr, fg, fb := f[0], f[1], f[2]
+
+	c000, c111 := l.at(r, g, b), l.at(r+1, g+1, b+1)
+
+	// Every case walks from c000 to c111 along the edges of the cube in the
+	// order of decreasing fractions.
+	var w [4]float64
+	var c1, c2 [3]float64
+	switch {
+	case fr >= fg && fg >= fb:
+		c1, c2 = l.at(r+1, g, b), l.at(r+1, g+1, b)
+		w = [4]float64{1 - fr, fr - fg, fg - fb, fb}
+	case fr >= fb && fb >= fg:
+		c1, c2 = l.at(r+1, g, b), l.at(r+1, g, b+1)
+		w = [4]float64{1 - fr, fr - fb, fb - fg, fg}
+	case fb >= fr && fr >= fg:
+		c1, c2 = l.at(r, g, b+1), l.at(r+1, g, b+1)
+		w = [4]float64{1 - fb, fb - fr, fr - fg, fg}
+	case fg >= fr && fr >= fb:
+		c1, c2 = l.at(r, g+1, b), l.at(r+1, g+1, b)
+		w = [4]float64{1 - fg, fg - fr, fr - fb, fb}
+	case fg >= fb && fb >= fr:
+		c1, c2 = l.at(r, g+1, b), l.at(r, g+1, b+1)
+		w = [4]float64{1 - fg, fg - fb, fb - fr, fr}
+	default:
+		c1, c2 = l.at(r, g, b+1), l.at(r, g+1, b+1)
+		w = [4]float64{1 - fb, fb - fg, fg - fr, fr}
+	}
+
+	var out [3]float64
+	for i := range out {
+		out[i] = w[0]*c000[i] + w[1]*c1[i] + w[2]*c2[i] + w[3]*c111[i]
+	}
+
+	return out
+}
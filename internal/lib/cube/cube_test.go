@@ -0,0 +1,88 @@
+package cube_test
+
+import (
+	"online-photo-editor/internal/lib/cube"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// identity is a 2x2x2 LUT that maps every color to itself.
+const identity = `TITLE "Identity #1"
+# Created by hand
+LUT_3D_SIZE 2
+
+0 0 0
+1 0 0
+0 1 0
+1 1 0
+0 0 1
+1 0 1
+0 1 1
+1 1 1
+`
+
+func TestParse(t *testing.T) {
+	lut, err := cube.Parse(strings.NewReader(identity))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Identity #1", lut.Title)
+	assert.Equal(t, 2, lut.Size)
+	assert.Len(t, lut.Table, 8)
+	assert.Equal(t, [3]float64{0, 0, 0}, lut.DomainMin)
+	assert.Equal(t, [3]float64{1, 1, 1}, lut.DomainMax)
+
+	c := [3]float64{0.25, 0.5, 0.75}
+	assert.InDeltaSlice(t, c[:], sliceOf(lut.Trilinear(c)), 1e-6)
+	assert.InDeltaSlice(t, c[:], sliceOf(lut.Tetrahedral(c)), 1e-6)
+}
+
+func TestParse_Domain(t *testing.T) {
+	data := strings.Replace(identity, "LUT_3D_SIZE 2", "LUT_3D_SIZE 2\nDOMAIN_MIN 0 0 0\nDOMAIN_MAX 2 4 1", 1)
+
+	lut, err := cube.Parse(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, [3]float64{2, 4, 1}, lut.DomainMax)
+
+	// Inputs are scaled into the domain and clamped to it.
+	out := lut.Trilinear([3]float64{1, 1, 2})
+	assert.InDeltaSlice(t, []float64{0.5, 0.25, 1}, sliceOf(out), 1e-6)
+}
+
+func TestParse_UnknownKeywords(t *testing.T) {
+	data := strings.Replace(identity, "LUT_3D_SIZE 2", "LUT_1D_INPUT_RANGE 0 1\nLUT_3D_SIZE 2 # two points per axis\nLUT_IN_VIDEO_RANGE\n# more header\nLUT_OUT_VIDEO_RANGE", 1)
+
+	lut, err := cube.Parse(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Len(t, lut.Table, 8)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"missing row", strings.Replace(identity, "1 1 1\n", "", 1), "expected 8 table entries, got 7"},
+		{"extra row", identity + "0 0 0\n", "too many table entries"},
+		{"short row", strings.Replace(identity, "1 1 1", "1 1", 1), "expected 3 values, got 2"},
+		{"invalid number", strings.Replace(identity, "1 1 1", "1 1 x", 1), `invalid number "x"`},
+		{"keyword after data", identity + "LUT_IN_VIDEO_RANGE\n", "keyword LUT_IN_VIDEO_RANGE after table data"},
+		{"missing size", "0 0 0\n", "data before LUT_3D_SIZE"},
+		{"size too large", "LUT_3D_SIZE 66\n", "LUT_3D_SIZE must be between 2 and 65"},
+		{"1D LUT", "LUT_1D_SIZE 1024\n", "1D LUTs are not supported"},
+		{"inverted domain", strings.Replace(identity, "LUT_3D_SIZE 2", "LUT_3D_SIZE 2\nDOMAIN_MIN 1 1 1\nDOMAIN_MAX 0 0 0", 1), "DOMAIN_MAX must be greater than DOMAIN_MIN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cube.Parse(strings.NewReader(tt.data))
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func sliceOf(c [3]float64) []float64 {
+	return c[:]
+}
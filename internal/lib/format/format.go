@@ -0,0 +1,304 @@
+package format
+
+import (
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+	"golang.org/x/image/webp"
+)
+
+// Format describes an image format known to the service. Formats without an
+// encoder can be uploaded and processed but not used as an output format.
+// Alpha formats keep fully transparent pixels and PartialAlpha ones keep
+// semi-transparent pixels as well. Lossy formats discard detail when
+// encoding; Preferred formats replace a lossless source format in negotiation
+// when the client accepts them as much.
+type Format struct {
+	Name         string
+	Aliases      []string
+	MIMEType     string
+	Extension    string
+	Magic        []string
+	Decode       func(r io.Reader) (image.Image, error)
+	Encode       func(w io.Writer, img image.Image) error
+	Alpha        bool
+	PartialAlpha bool
+	Animation    bool
+	Lossy        bool
+	Preferred    bool
+}
+
+// Transparency is the kind of alpha an image needs its output format to keep.
+type Transparency int
+
+const (
+	Opaque Transparency = iota
+	// Transparent images have fully transparent pixels but no
+	// semi-transparent ones.
+	Transparent
+	// Translucent images have semi-transparent pixels.
+	Translucent
+)
+
+var formats = []*Format{
+	{
+		Name:      "jpeg",
+		Aliases:   []string{"jpg", "jpe"},
+		MIMEType:  "image/jpeg",
+		Extension: ".jpg",
+		Magic:     []string{"\xff\xd8"},
+		Decode:    jpeg.Decode,
+		Encode: func(w io.Writer, img image.Image) error {
+			return jpeg.Encode(w, img, nil)
+		},
+		Lossy: true,
+	},
+	{
+		Name:         "png",
+		MIMEType:     "image/png",
+		Extension:    ".png",
+		Magic:        []string{"\x89PNG\r\n\x1a\n"},
+		Decode:       png.Decode,
+		Encode:       png.Encode,
+		Alpha:        true,
+		PartialAlpha: true,
+	},
+	{
+		Name:      "gif",
+		MIMEType:  "image/gif",
+		Extension: ".gif",
+		Magic:     []string{"GIF87a", "GIF89a"},
+		Decode:    gif.Decode,
+		Encode: func(w io.Writer, img image.Image) error {
+			return gif.Encode(w, img, nil)
+		},
+		Alpha:     true,
+		Animation: true,
+	},
+	{
+		Name:         "bmp",
+		MIMEType:     "image/bmp",
+		Extension:    ".bmp",
+		Magic:        []string{"BM"},
+		Decode:       bmp.Decode,
+		Encode:       bmp.Encode,
+		Alpha:        true,
+		PartialAlpha: true,
+	},
+	{
+		Name:      "tiff",
+		Aliases:   []string{"tif"},
+		MIMEType:  "image/tiff",
+		Extension: ".tiff",
+		Magic:     []string{"II*\x00", "MM\x00*"},
+		Decode:    tiff.Decode,
+		Encode: func(w io.Writer, img image.Image) error {
+			return tiff.Encode(w, img, nil)
+		},
+		Alpha:        true,
+		PartialAlpha: true,
+	},
+	{
+		Name:         "webp",
+		MIMEType:     "image/webp",
+		Extension:    ".webp",
+		Magic:        []string{"RIFF????WEBPVP8"},
+		Decode:       webp.Decode,
+		Encode:       encodeWebP,
+		Alpha:        true,
+		PartialAlpha: true,
+		Preferred:    true,
+	},
+}
+
+// Lookup finds a format by name, alias or file extension, ignoring case and a
+// leading dot.
+func Lookup(name string) (*Format, bool) {
+	name = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), ".")
+
+	for _, f := range formats {
+		if f.Name == name || f.Extension[1:] == name {
+			return f, true
+		}
+		for _, alias := range f.Aliases {
+			if alias == name {
+				return f, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// ByMIME finds a format by its MIME type.
+func ByMIME(mimeType string) (*Format, bool) {
+	for _, f := range formats {
+		if f.MIMEType == mimeType {
+			return f, true
+		}
+	}
+
+	return nil, false
+}
+
+// Sniff detects the format of an encoded image from its leading bytes.
+func Sniff(header []byte) (*Format, bool) {
+	for _, f := range formats {
+		for _, magic := range f.Magic {
+			if match(magic, header) {
+				return f, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Encodable returns the names of all formats that can be used as output.
+func Encodable() []string {
+	var names []string
+	for _, f := range formats {
+		if f.CanEncode() {
+			names = append(names, f.Name)
+		}
+	}
+
+	return names
+}
+
+func (f *Format) CanEncode() bool {
+	return f.Encode != nil
+}
+
+func match(magic string, b []byte) bool {
+	if len(magic) > len(b) {
+		return false
+	}
+	for i := 0; i < len(magic); i++ {
+		if magic[i] != '?' && magic[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Negotiate picks the output format for an Accept header value. The source
+// format wins unless the client explicitly prefers another encodable format,
+// or explicitly lists a preferred format at least as high as a lossless
+// source. A lossy source is kept in that case, since the only WebP encoder
+// is lossless and would make the file larger. Formats without alpha are
+// skipped when they cannot keep the image's transparency.
+func Negotiate(accept string, source string, transparency Transparency) *Format {
+	ranges := parseAccept(accept)
+
+	eligible := func(f *Format) bool {
+		switch {
+		case f == nil || !f.CanEncode():
+			return false
+		case transparency == Translucent:
+			return f.PartialAlpha
+		case transparency == Transparent:
+			return f.Alpha
+		}
+		return true
+	}
+
+	var best *Format
+	bestQ := 0.0
+
+	src, _ := Lookup(source)
+	if eligible(src) {
+		best, bestQ = src, 1.0
+		if len(ranges) > 0 {
+			bestQ = quality(ranges, src.MIMEType, false)
+		}
+	}
+
+	for _, f := range formats {
+		if !eligible(f) || f == src {
+			continue
+		}
+		q := quality(ranges, f.MIMEType, true)
+		tie := q > 0 && q == bestQ && best == src && f.Preferred && !src.Lossy
+		if q > bestQ || tie {
+			best, bestQ = f, q
+		}
+	}
+
+	if best != nil {
+		return best
+	}
+
+	for _, f := range formats {
+		if eligible(f) {
+			return f
+		}
+	}
+
+	return formats[0]
+}
+
+type mediaRange struct {
+	mimeType string
+	q        float64
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		mimeType := strings.ToLower(strings.TrimSpace(fields[0]))
+		if mimeType == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = v
+			}
+		}
+
+		ranges = append(ranges, mediaRange{mimeType: mimeType, q: q})
+	}
+
+	return ranges
+}
+
+// quality returns the q-value the ranges assign to mimeType, preferring the
+// most specific match. With exact set, wildcards are ignored.
+func quality(ranges []mediaRange, mimeType string, exact bool) float64 {
+	q, specificity := 0.0, -1
+	typ, _, _ := strings.Cut(mimeType, "/")
+
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.mimeType == mimeType:
+			s = 2
+		case exact:
+		case r.mimeType == typ+"/*":
+			s = 1
+		case r.mimeType == "*/*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+
+	return q
+}
@@ -0,0 +1,35 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		source string
+		alpha  Transparency
+		want   string
+	}{
+		{"", "png", Opaque, "png"},
+		{"*/*", "jpg", Opaque, "jpeg"},
+		{"image/avif,image/webp,*/*", "png", Opaque, "webp"},
+		{"image/webp,image/*", "png", Translucent, "webp"},
+		{"image/webp,image/*", "jpg", Opaque, "jpeg"},
+		{"image/webp;q=0.5,image/png", "png", Opaque, "png"},
+		{"image/webp,image/jpeg;q=0.9", "jpg", Opaque, "webp"},
+		{"image/gif,image/*;q=0.5", "png", Opaque, "gif"},
+		{"image/jpeg", "png", Translucent, "png"},
+		{"image/jpeg", "webp", Translucent, "webp"},
+		{"image/gif", "png", Transparent, "gif"},
+		{"image/gif", "png", Translucent, "png"},
+		{"image/gif,image/*", "gif", Translucent, "png"},
+	}
+
+	for _, tt := range tests {
+		got := Negotiate(tt.accept, tt.source, tt.alpha)
+		assert.Equal(t, tt.want, got.Name, "Accept %q, source %s, transparency %v", tt.accept, tt.source, tt.alpha)
+	}
+}
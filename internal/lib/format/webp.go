@@ -0,0 +1,411 @@
+package format
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"io"
+	"slices"
+)
+
+const (
+	// maxWebPDimension is the largest width or height VP8L can store.
+	maxWebPDimension = 1 << 14
+	// maxCopyLength is the longest backward reference VP8L can express.
+	maxCopyLength = 4096
+
+	literalCodes  = 256
+	lengthCodes   = 24
+	distanceCodes = 40
+
+	// The plane codes of the pixel above and of the previous pixel.
+	aboveCode    = 1
+	previousCode = 2
+)
+
+// codeLengthOrder is the order in which the lengths of the code length code
+// are stored.
+var codeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// encodeWebP writes img as a lossless WebP (VP8L) image. It applies the
+// subtract green transform and replaces runs of pixels repeating the previous
+// pixel or the row above with backward references; there is no color cache
+// and a single set of prefix codes covers the whole image.
+func encodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	if b.Dx() < 1 || b.Dy() < 1 || b.Dx() > maxWebPDimension || b.Dy() > maxWebPDimension {
+		return errors.New("webp: image size must be between 1 and 16384 pixels per side")
+	}
+
+	src := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Rect, img, b.Min, draw.Src)
+
+	alpha := false
+	argb := make([]uint32, b.Dx()*b.Dy())
+	for i := range argb {
+		p := src.Pix[i*4 : i*4+4]
+		r, g, bl, a := p[0], p[1], p[2], p[3]
+		argb[i] = uint32(a)<<24 | uint32(r-g)<<16 | uint32(g)<<8 | uint32(bl-g)
+		alpha = alpha || a != 0xff
+	}
+
+	green := make([]int, literalCodes+lengthCodes)
+	red, blue, alphas := make([]int, literalCodes), make([]int, literalCodes), make([]int, literalCodes)
+	distance := make([]int, distanceCodes)
+	backwardReferences(argb, b.Dx(), func(t token) {
+		if t.length == 0 {
+			green[t.argb>>8&0xff]++
+			red[t.argb>>16&0xff]++
+			blue[t.argb&0xff]++
+			alphas[t.argb>>24]++
+			return
+		}
+		code, _, _ := prefixEncode(t.length)
+		green[literalCodes+code]++
+		code, _, _ = prefixEncode(t.plane)
+		distance[code]++
+	})
+
+	var bw bitWriter
+	bw.write(0x2f, 8)
+	bw.write(uint32(b.Dx()-1), 14)
+	bw.write(uint32(b.Dy()-1), 14)
+	bw.write(boolBit(alpha), 1)
+	bw.write(0, 3)
+
+	// A subtract green transform, then no further transforms, no color cache
+	// and no meta prefix codes.
+	bw.write(1, 1)
+	bw.write(2, 2)
+	bw.write(0, 1)
+	bw.write(0, 1)
+	bw.write(0, 1)
+
+	codes := [5]prefixCode{
+		newPrefixCode(green, 15),
+		newPrefixCode(red, 15),
+		newPrefixCode(blue, 15),
+		newPrefixCode(alphas, 15),
+		newPrefixCode(distance, 15),
+	}
+	for _, c := range codes {
+		c.writeHeader(&bw)
+	}
+
+	backwardReferences(argb, b.Dx(), func(t token) {
+		if t.length == 0 {
+			codes[0].writeSymbol(&bw, int(t.argb>>8&0xff))
+			codes[1].writeSymbol(&bw, int(t.argb>>16&0xff))
+			codes[2].writeSymbol(&bw, int(t.argb&0xff))
+			codes[3].writeSymbol(&bw, int(t.argb>>24))
+			return
+		}
+		code, extra, bits := prefixEncode(t.length)
+		codes[0].writeSymbol(&bw, literalCodes+code)
+		bw.write(extra, bits)
+		code, extra, bits = prefixEncode(t.plane)
+		codes[4].writeSymbol(&bw, code)
+		bw.write(extra, bits)
+	})
+
+	data := bw.flush()
+	padding := len(data) & 1
+
+	header := make([]byte, 20)
+	copy(header, "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(12+len(data)+padding))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if padding != 0 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+
+	return nil
+}
+
+// token is either a literal pixel or, with a non-zero length, a copy of
+// length pixels from the position given by the plane code.
+type token struct {
+	argb   uint32
+	length int
+	plane  int
+}
+
+// backwardReferences splits the pixels into literals and copies of the
+// previous pixel or the row above, greedily taking the longer match, and
+// passes them to emit in order. The split only depends on the pixels, so the
+// encoder runs it once to count the symbols and once to write them.
+func backwardReferences(argb []uint32, width int, emit func(token)) {
+	match := func(i, dist int) int {
+		n := 0
+		for i+n < len(argb) && n < maxCopyLength && argb[i+n] == argb[i+n-dist] {
+			n++
+		}
+		return n
+	}
+
+	for i := 0; i < len(argb); {
+		length, plane := 0, 0
+		if i >= 1 {
+			length, plane = match(i, 1), previousCode
+		}
+		if i >= width {
+			if n := match(i, width); n > length {
+				length, plane = n, aboveCode
+			}
+		}
+
+		// A literal is four symbols, so only runs of at least two pixels are
+		// worth a reference.
+		if length < 2 {
+			emit(token{argb: argb[i]})
+			i++
+			continue
+		}
+		emit(token{length: length, plane: plane})
+		i += length
+	}
+}
+
+// prefixEncode splits a length or distance of at least 1 into its prefix
+// code and the extra bits that follow it.
+func prefixEncode(v int) (code int, extra uint32, bits uint) {
+	d := v - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+
+	h := 0
+	for d>>(h+1) != 0 {
+		h++
+	}
+	bits = uint(h - 1)
+
+	return 2*h + (d>>(h-1))&1, uint32(d) & (1<<bits - 1), bits
+}
+
+// prefixCode is a canonical Huffman code.
+type prefixCode struct {
+	lengths []uint8
+	// codes holds the codes bit-reversed, since they are read from the
+	// least significant bit on.
+	codes []uint16
+	// single is set when only one symbol occurs, which takes no bits.
+	single bool
+}
+
+func newPrefixCode(counts []int, maxLength int) prefixCode {
+	c := prefixCode{lengths: huffmanLengths(counts, maxLength), codes: make([]uint16, len(counts))}
+
+	var lengthCounts [16]int
+	used := 0
+	for _, l := range c.lengths {
+		if l != 0 {
+			lengthCounts[l]++
+			used++
+		}
+	}
+	c.single = used == 1
+
+	var next [16]int
+	code := 0
+	for l := 1; l < len(next); l++ {
+		code = (code + lengthCounts[l-1]) << 1
+		next[l] = code
+	}
+	for symbol, l := range c.lengths {
+		if l == 0 {
+			continue
+		}
+		reversed := 0
+		for i := 0; i < int(l); i++ {
+			reversed |= (next[l] >> i & 1) << (int(l) - 1 - i)
+		}
+		c.codes[symbol] = uint16(reversed)
+		next[l]++
+	}
+
+	return c
+}
+
+func (c prefixCode) writeSymbol(bw *bitWriter, symbol int) {
+	if !c.single {
+		bw.write(uint32(c.codes[symbol]), uint(c.lengths[symbol]))
+	}
+}
+
+// writeHeader stores the code lengths, themselves compressed with a code
+// length code that has runs of zeros.
+func (c prefixCode) writeHeader(bw *bitWriter) {
+	type lengthToken struct {
+		symbol int
+		extra  uint32
+		bits   uint
+	}
+
+	var tokens []lengthToken
+	for i := 0; i < len(c.lengths); {
+		if c.lengths[i] != 0 {
+			tokens = append(tokens, lengthToken{symbol: int(c.lengths[i])})
+			i++
+			continue
+		}
+
+		run := 0
+		for i+run < len(c.lengths) && c.lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run >= 11:
+			tokens = append(tokens, lengthToken{symbol: 18, extra: uint32(run - 11), bits: 7})
+		case run >= 3:
+			tokens = append(tokens, lengthToken{symbol: 17, extra: uint32(run - 3), bits: 3})
+		default:
+			for j := 0; j < run; j++ {
+				tokens = append(tokens, lengthToken{symbol: 0})
+			}
+		}
+		i += run
+	}
+
+	counts := make([]int, len(codeLengthOrder))
+	for _, t := range tokens {
+		counts[t.symbol]++
+	}
+	lengthCode := newPrefixCode(counts, 7)
+
+	n := len(codeLengthOrder)
+	for n > 4 && lengthCode.lengths[codeLengthOrder[n-1]] == 0 {
+		n--
+	}
+
+	// A normal code, not the simple one- or two-symbol form.
+	bw.write(0, 1)
+	bw.write(uint32(n-4), 4)
+	for _, symbol := range codeLengthOrder[:n] {
+		bw.write(uint32(lengthCode.lengths[symbol]), 3)
+	}
+	// Lengths are given for the whole alphabet.
+	bw.write(0, 1)
+
+	for _, t := range tokens {
+		lengthCode.writeSymbol(bw, t.symbol)
+		bw.write(t.extra, t.bits)
+	}
+}
+
+// huffmanLengths returns the code lengths, at most maxLength bits, of a
+// Huffman code for the symbol counts. Symbols that never occur get no code,
+// except that an empty alphabet gets one for symbol 0 since decoders reject
+// empty codes. Counts are halved until the code fits into maxLength.
+func huffmanLengths(counts []int, maxLength int) []uint8 {
+	lengths := make([]uint8, len(counts))
+
+	var symbols []int
+	for s, n := range counts {
+		if n > 0 {
+			symbols = append(symbols, s)
+		}
+	}
+	switch len(symbols) {
+	case 0:
+		lengths[0] = 1
+		return lengths
+	case 1:
+		lengths[symbols[0]] = 1
+		return lengths
+	}
+
+	type node struct {
+		count  int
+		parent int
+	}
+
+	for shift := 0; ; shift++ {
+		scaled := func(s int) int {
+			return max(counts[s]>>shift, 1)
+		}
+		slices.SortStableFunc(symbols, func(a, b int) int {
+			return scaled(a) - scaled(b)
+		})
+
+		// Two queues: the sorted leaves and the internal nodes, which are
+		// created in increasing order of count.
+		nodes := make([]node, 0, 2*len(symbols)-1)
+		for _, s := range symbols {
+			nodes = append(nodes, node{count: scaled(s), parent: -1})
+		}
+		leaf, internal := 0, len(symbols)
+		pick := func() int {
+			if leaf < len(symbols) && (internal == len(nodes) || nodes[leaf].count <= nodes[internal].count) {
+				leaf++
+				return leaf - 1
+			}
+			internal++
+			return internal - 1
+		}
+		for len(nodes) < cap(nodes) {
+			a, b := pick(), pick()
+			nodes = append(nodes, node{count: nodes[a].count + nodes[b].count, parent: -1})
+			nodes[a].parent, nodes[b].parent = len(nodes)-1, len(nodes)-1
+		}
+
+		depth := make([]int, len(nodes))
+		longest := 0
+		for i := len(nodes) - 2; i >= 0; i-- {
+			depth[i] = depth[nodes[i].parent] + 1
+			longest = max(longest, depth[i])
+		}
+		if longest > maxLength {
+			continue
+		}
+
+		for i, s := range symbols {
+			lengths[s] = uint8(depth[i])
+		}
+		return lengths
+	}
+}
+
+// bitWriter packs values from the least significant bit on.
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	nacc uint
+}
+
+func (bw *bitWriter) write(v uint32, n uint) {
+	bw.acc |= uint64(v) << bw.nacc
+	bw.nacc += n
+	for bw.nacc >= 8 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc >>= 8
+		bw.nacc -= 8
+	}
+}
+
+func (bw *bitWriter) flush() []byte {
+	if bw.nacc > 0 {
+		bw.buf = append(bw.buf, byte(bw.acc))
+		bw.acc, bw.nacc = 0, 0
+	}
+
+	return bw.buf
+}
+
+func boolBit(b bool) uint32 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
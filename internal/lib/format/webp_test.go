@@ -0,0 +1,103 @@
+package format
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/webp"
+)
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	rng.Read(noise.Pix)
+
+	// Flat areas, repeated rows and a gradient exercise the backward
+	// references and codes of very different shapes.
+	flat := image.NewNRGBA(image.Rect(0, 0, 300, 40))
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 300; x++ {
+			c := color.NRGBA{R: 200, G: 30, B: 90, A: 255}
+			if x > 150 {
+				c = color.NRGBA{R: uint8(x), G: uint8(x / 2), B: 0, A: uint8(255 - x/4)}
+			}
+			if y%7 == 3 {
+				c = color.NRGBA{R: uint8(rng.Intn(256)), A: 255}
+			}
+			flat.SetNRGBA(x, y, c)
+		}
+	}
+
+	single := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	single.SetNRGBA(0, 0, color.NRGBA{R: 1, G: 2, B: 3, A: 4})
+
+	offset := image.NewNRGBA(image.Rect(5, 5, 15, 12))
+	for i := range offset.Pix {
+		offset.Pix[i] = uint8(i % 7 * 30)
+	}
+
+	for name, img := range map[string]*image.NRGBA{"noise": noise, "flat": flat, "single": single, "offset": offset} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.NoError(t, encodeWebP(&buf, img))
+
+			f, ok := Sniff(buf.Bytes())
+			assert.True(t, ok)
+			assert.Equal(t, "webp", f.Name)
+
+			decoded, err := webp.Decode(&buf)
+			if !assert.NoError(t, err) {
+				return
+			}
+			got, ok := decoded.(*image.NRGBA)
+			if !assert.True(t, ok) {
+				return
+			}
+			assert.Equal(t, img.Rect.Size(), got.Rect.Size())
+			for y := 0; y < img.Rect.Dy(); y++ {
+				for x := 0; x < img.Rect.Dx(); x++ {
+					want := img.NRGBAAt(img.Rect.Min.X+x, img.Rect.Min.Y+y)
+					if c := got.NRGBAAt(x, y); c != want {
+						t.Fatalf("pixel (%d, %d) is %v, want %v", x, y, c, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeWebP_CompressesFlatImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 1000, 1000))
+	for i := range img.Pix {
+		img.Pix[i] = 0x80
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, encodeWebP(&buf, img))
+	assert.Less(t, buf.Len(), 2000)
+}
+
+func TestHuffmanLengths_LimitsLength(t *testing.T) {
+	// Fibonacci counts give the deepest possible Huffman tree.
+	counts := make([]int, 30)
+	a, b := 1, 1
+	for i := range counts {
+		counts[i] = a
+		a, b = b, a+b
+	}
+
+	lengths := huffmanLengths(counts, 15)
+
+	kraft := 0.0
+	for _, l := range lengths {
+		assert.LessOrEqual(t, int(l), 15)
+		assert.NotZero(t, l)
+		kraft += 1 / float64(int(1)<<l)
+	}
+	assert.Equal(t, 1.0, kraft)
+}
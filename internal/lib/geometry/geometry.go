@@ -0,0 +1,91 @@
+package geometry
+
+import (
+	"image"
+	"math"
+)
+
+// MaxCoordinate bounds the coordinates accepted in request params so that
+// the geometry stays precise in the rasterizer's float32 arithmetic. The
+// validate tags below repeat it.
+const MaxCoordinate = 1e6
+
+// Rect is a rectangle as accepted in request params.
+type Rect struct {
+	X      int `json:"x" validate:"min=0,max=1000000"`
+	Y      int `json:"y" validate:"min=0,max=1000000"`
+	Width  int `json:"width" validate:"required,min=1,max=1000000"`
+	Height int `json:"height" validate:"required,min=1,max=1000000"`
+}
+
+// Rectangle converts r into image coordinates relative to bounds.
+func (r Rect) Rectangle(bounds image.Rectangle) image.Rectangle {
+	return image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height).Add(bounds.Min).Intersect(bounds)
+}
+
+// Point is a position in image coordinates as accepted in request params.
+type Point struct {
+	X float64 `json:"x" validate:"min=-1000000,max=1000000"`
+	Y float64 `json:"y" validate:"min=-1000000,max=1000000"`
+}
+
+// InRange reports whether every point is within ±MaxCoordinate.
+func InRange(pts []Point) bool {
+	for _, pt := range pts {
+		if !(math.Abs(pt.X) <= MaxCoordinate && math.Abs(pt.Y) <= MaxCoordinate) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ClipPolygon clips a closed polygon to the rectangle from min to max with
+// the Sutherland–Hodgman algorithm. Concave polygons keep their area, though
+// parts that leave the rectangle become zero-width edges along its border.
+func ClipPolygon(pts []Point, min, max Point) []Point {
+	edges := []struct {
+		inside func(p Point) bool
+		cross  func(a, b Point) Point
+	}{
+		{
+			func(p Point) bool { return p.X >= min.X },
+			func(a, b Point) Point { return Point{X: min.X, Y: a.Y + (b.Y-a.Y)*(min.X-a.X)/(b.X-a.X)} },
+		},
+		{
+			func(p Point) bool { return p.X <= max.X },
+			func(a, b Point) Point { return Point{X: max.X, Y: a.Y + (b.Y-a.Y)*(max.X-a.X)/(b.X-a.X)} },
+		},
+		{
+			func(p Point) bool { return p.Y >= min.Y },
+			func(a, b Point) Point { return Point{X: a.X + (b.X-a.X)*(min.Y-a.Y)/(b.Y-a.Y), Y: min.Y} },
+		},
+		{
+			func(p Point) bool { return p.Y <= max.Y },
+			func(a, b Point) Point { return Point{X: a.X + (b.X-a.X)*(max.Y-a.Y)/(b.Y-a.Y), Y: max.Y} },
+		},
+	}
+
+	for _, edge := range edges {
+		if len(pts) == 0 {
+			break
+		}
+
+		var out []Point
+		prev := pts[len(pts)-1]
+		for _, p := range pts {
+			switch {
+			case edge.inside(p) && edge.inside(prev):
+				out = append(out, p)
+			case edge.inside(p):
+				out = append(out, edge.cross(prev, p), p)
+			case edge.inside(prev):
+				out = append(out, edge.cross(prev, p))
+			}
+			prev = p
+		}
+		pts = out
+	}
+
+	return pts
+}
@@ -0,0 +1,59 @@
+package gravity
+
+import (
+	"fmt"
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+var anchors = map[string]imaging.Anchor{
+	"center":    imaging.Center,
+	"north":     imaging.Top,
+	"south":     imaging.Bottom,
+	"east":      imaging.Right,
+	"west":      imaging.Left,
+	"northeast": imaging.TopRight,
+	"northwest": imaging.TopLeft,
+	"southeast": imaging.BottomRight,
+	"southwest": imaging.BottomLeft,
+}
+
+// Parse returns the anchor for a gravity name. An empty name means center.
+func Parse(name string) (imaging.Anchor, error) {
+	const op = "lib.gravity.Parse"
+
+	if name == "" {
+		return imaging.Center, nil
+	}
+
+	anchor, ok := anchors[name]
+	if !ok {
+		return imaging.Center, fmt.Errorf("%s: unknown gravity %q", op, name)
+	}
+
+	return anchor, nil
+}
+
+// Point returns the top-left corner of a w x h box placed inside bounds
+// according to anchor.
+func Point(bounds image.Rectangle, w, h int, anchor imaging.Anchor) image.Point {
+	x := bounds.Min.X + (bounds.Dx()-w)/2
+	y := bounds.Min.Y + (bounds.Dy()-h)/2
+
+	switch anchor {
+	case imaging.TopLeft, imaging.Left, imaging.BottomLeft:
+		x = bounds.Min.X
+	case imaging.TopRight, imaging.Right, imaging.BottomRight:
+		x = bounds.Max.X - w
+	}
+
+	switch anchor {
+	case imaging.TopLeft, imaging.Top, imaging.TopRight:
+		y = bounds.Min.Y
+	case imaging.BottomLeft, imaging.Bottom, imaging.BottomRight:
+		y = bounds.Max.Y - h
+	}
+
+	return image.Pt(x, y)
+}
@@ -0,0 +1,152 @@
+package histogram
+
+import "math"
+
+// Histogram counts the occurrences of every 8-bit value.
+type Histogram [256]int
+
+func (h *Histogram) Total() int {
+	total := 0
+	for _, n := range h {
+		total += n
+	}
+
+	return total
+}
+
+// Percentile returns the smallest value at or below which at least p percent
+// of the samples lie.
+func (h *Histogram) Percentile(p float64) uint8 {
+	total := h.Total()
+	target := int(math.Ceil(p / 100 * float64(total)))
+
+	sum := 0
+	for v, n := range h {
+		sum += n
+		if sum >= max(target, 1) {
+			return uint8(v)
+		}
+	}
+
+	return 255
+}
+
+// Stretch returns a lookup table that maps the range between the given
+// percentiles onto the full 8-bit range. If the range is empty the table is
+// the identity.
+func (h *Histogram) Stretch(clipLow, clipHigh float64) [256]uint8 {
+	lo, hi := h.Percentile(clipLow), h.Percentile(100-clipHigh)
+
+	var lut [256]uint8
+	for v := range lut {
+		switch {
+		case hi <= lo:
+			lut[v] = uint8(v)
+		case v <= int(lo):
+			lut[v] = 0
+		case v >= int(hi):
+			lut[v] = 255
+		default:
+			lut[v] = uint8(math.Round(float64(v-int(lo)) * 255 / float64(hi-lo)))
+		}
+	}
+
+	return lut
+}
+
+// Equalize returns a lookup table that spreads the values evenly over the
+// 8-bit range according to the cumulative distribution. An empty histogram
+// yields the identity.
+func (h *Histogram) Equalize() [256]uint8 {
+	var lut [256]uint8
+
+	total := h.Total()
+	if total == 0 {
+		for v := range lut {
+			lut[v] = uint8(v)
+		}
+		return lut
+	}
+
+	// The lowest occupied value is mapped to 0.
+	first := 0
+	for h[first] == 0 {
+		first++
+	}
+	if total == h[first] {
+		for v := range lut {
+			lut[v] = uint8(v)
+		}
+		return lut
+	}
+
+	sum := 0
+	for v := range lut {
+		sum += h[v]
+		lut[v] = uint8(math.Round(float64(max(sum-h[first], 0)) * 255 / float64(total-h[first])))
+	}
+
+	return lut
+}
+
+// Clip limits every bin to limit and redistributes the excess evenly over
+// all bins.
+func (h *Histogram) Clip(limit int) {
+	excess := 0
+	for v, n := range h {
+		if n > limit {
+			excess += n - limit
+			h[v] = limit
+		}
+	}
+
+	each, rest := excess/len(h), excess%len(h)
+	for v := range h {
+		h[v] += each
+	}
+
+	// The remainder is spread with an even stride over the range.
+	if rest > 0 {
+		step := len(h) / rest
+		for v := 0; v < len(h) && rest > 0; v += step {
+			h[v]++
+			rest--
+		}
+	}
+}
+
+// Otsu returns the threshold that best separates the values into two classes
+// by maximizing the variance between them. Values above the threshold belong
+// to the upper class.
+func (h *Histogram) Otsu() uint8 {
+	total := h.Total()
+	if total == 0 {
+		return 127
+	}
+
+	sum := 0.0
+	for v, n := range h {
+		sum += float64(v * n)
+	}
+
+	var best uint8
+	bestVariance := -1.0
+	sumLow, countLow := 0.0, 0
+	for t := 0; t < 255; t++ {
+		countLow += h[t]
+		sumLow += float64(t * h[t])
+		countHigh := total - countLow
+		if countLow == 0 || countHigh == 0 {
+			continue
+		}
+
+		meanLow := sumLow / float64(countLow)
+		meanHigh := (sum - sumLow) / float64(countHigh)
+		variance := float64(countLow) * float64(countHigh) * (meanLow - meanHigh) * (meanLow - meanHigh)
+		if variance > bestVariance {
+			best, bestVariance = uint8(t), variance
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,224 @@
+package mask
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"online-photo-editor/internal/lib/geometry"
+
+	"github.com/disintegration/imaging"
+	"golang.org/x/image/vector"
+)
+
+// Region is a rectangle, an ellipse or a polygon in image coordinates.
+// Exactly one of them must be set.
+type Region struct {
+	Rect    *geometry.Rect   `json:"rect"`
+	Ellipse *Ellipse         `json:"ellipse"`
+	Polygon []geometry.Point `json:"polygon" validate:"omitempty,min=3,max=256,dive"`
+}
+
+// Ellipse is an axis-aligned ellipse given by its center and radii.
+type Ellipse struct {
+	X       float64 `json:"x" validate:"min=-1000000,max=1000000"`
+	Y       float64 `json:"y" validate:"min=-1000000,max=1000000"`
+	RadiusX float64 `json:"radius_x" validate:"required,gt=0,max=1000000"`
+	RadiusY float64 `json:"radius_y" validate:"required,gt=0,max=1000000"`
+}
+
+// shapes returns how many shapes of the region are set.
+func (region Region) shapes() int {
+	n := 0
+	if region.Rect != nil {
+		n++
+	}
+	if region.Ellipse != nil {
+		n++
+	}
+	if region.Polygon != nil {
+		n++
+	}
+
+	return n
+}
+
+// inRange reports whether every coordinate and size of the region is within
+// ±geometry.MaxCoordinate.
+func (region Region) inRange() bool {
+	switch {
+	case region.Rect != nil:
+		r := region.Rect
+		return geometry.InRange([]geometry.Point{
+			{X: float64(r.X), Y: float64(r.Y)},
+			{X: float64(r.Width), Y: float64(r.Height)},
+		})
+	case region.Ellipse != nil:
+		e := region.Ellipse
+		return geometry.InRange([]geometry.Point{{X: e.X, Y: e.Y}, {X: e.RadiusX, Y: e.RadiusY}})
+	default:
+		return geometry.InRange(region.Polygon)
+	}
+}
+
+// polygon returns the outline of the region.
+func (region Region) polygon() []geometry.Point {
+	switch {
+	case region.Rect != nil:
+		r := region.Rect
+		x0, y0 := float64(r.X), float64(r.Y)
+		x1, y1 := x0+float64(r.Width), y0+float64(r.Height)
+		return []geometry.Point{{X: x0, Y: y0}, {X: x1, Y: y0}, {X: x1, Y: y1}, {X: x0, Y: y1}}
+	case region.Ellipse != nil:
+		return region.Ellipse.polygon()
+	default:
+		return region.Polygon
+	}
+}
+
+// polygon approximates the ellipse with a polygon fine enough that the error
+// stays below a tenth of a pixel.
+func (e Ellipse) polygon() []geometry.Point {
+	r := max(e.RadiusX, e.RadiusY)
+	n := min(max(int(math.Ceil(math.Pi/math.Acos(1-0.1/max(r, 0.1)))), 8), 8192)
+
+	pts := make([]geometry.Point, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		pts[i] = geometry.Point{X: e.X + e.RadiusX*math.Cos(a), Y: e.Y + e.RadiusY*math.Sin(a)}
+	}
+
+	return pts
+}
+
+// Regions rasterizes the union of the regions into an anti-aliased mask that
+// covers bounds. Coordinates are relative to bounds.Min.
+func Regions(regions []Region, bounds image.Rectangle) (*image.Alpha, error) {
+	const op = "lib.mask.Regions"
+
+	polygons := make([][]geometry.Point, len(regions))
+	for i, region := range regions {
+		if region.shapes() != 1 {
+			return nil, fmt.Errorf("%s: region %d must have exactly one of rect, ellipse or polygon", op, i)
+		}
+		if !region.inRange() {
+			return nil, fmt.Errorf("%s: region %d: coordinates must be within ±%d", op, i, int(geometry.MaxCoordinate))
+		}
+		polygons[i] = region.polygon()
+	}
+
+	// The regions are oriented alike so that overlapping regions with
+	// opposite winding do not cancel out.
+	covered := Polygons(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), polygons, true)
+
+	m := image.NewAlpha(bounds)
+	draw.Draw(m, covered.Rect.Add(bounds.Min), covered, covered.Rect.Min, draw.Src)
+
+	return m, nil
+}
+
+// Polygons renders the polygons into a mask over the part of bounds they
+// cover. They are clipped to that area first so that far away points cannot
+// upset the rasterizer. With orient set every polygon is added with the same
+// winding, so that overlapping polygons add up instead of cancelling out.
+func Polygons(bounds image.Rectangle, polygons [][]geometry.Point, orient bool) *image.Alpha {
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, polygon := range polygons {
+		for _, pt := range polygon {
+			minX, minY = min(minX, pt.X), min(minY, pt.Y)
+			maxX, maxY = max(maxX, pt.X), max(maxY, pt.Y)
+		}
+	}
+	if !(minX <= maxX && minY <= maxY) {
+		return image.NewAlpha(image.Rectangle{})
+	}
+
+	r := image.Rect(
+		int(max(math.Floor(minX)-1, float64(bounds.Min.X))),
+		int(max(math.Floor(minY)-1, float64(bounds.Min.Y))),
+		int(min(math.Ceil(maxX)+1, float64(bounds.Max.X))),
+		int(min(math.Ceil(maxY)+1, float64(bounds.Max.Y))),
+	).Intersect(bounds)
+	m := image.NewAlpha(r)
+	if r.Empty() {
+		return m
+	}
+
+	off := geometry.Point{X: float64(r.Min.X), Y: float64(r.Min.Y)}
+	clipMin := geometry.Point{X: off.X - 1, Y: off.Y - 1}
+	clipMax := geometry.Point{X: float64(r.Max.X) + 1, Y: float64(r.Max.Y) + 1}
+
+	z := vector.NewRasterizer(r.Dx(), r.Dy())
+	for _, polygon := range polygons {
+		addPolygon(z, geometry.ClipPolygon(polygon, clipMin, clipMax), off, orient)
+	}
+	z.Draw(m, r, image.Opaque, image.Point{})
+
+	return m
+}
+
+// addPolygon adds a polygon to z, shifted by -off, reversing it when orient
+// is set and it winds the other way.
+func addPolygon(z *vector.Rasterizer, pts []geometry.Point, off geometry.Point, orient bool) {
+	if len(pts) < 3 {
+		return
+	}
+
+	area := 0.0
+	for i, a := range pts {
+		b := pts[(i+1)%len(pts)]
+		area += a.X*b.Y - b.X*a.Y
+	}
+
+	at := func(i int) (float32, float32) {
+		if orient && area > 0 {
+			i = len(pts) - 1 - i
+		}
+		return float32(pts[i].X - off.X), float32(pts[i].Y - off.Y)
+	}
+
+	z.MoveTo(at(0))
+	for i := 1; i < len(pts); i++ {
+		z.LineTo(at(i))
+	}
+	z.ClosePath()
+}
+
+// Blend returns base with overlay drawn over it where m is set, mixing the
+// two in proportion to the mask value. All three must have the same size.
+func Blend(base, overlay image.Image, m *image.Alpha) *image.NRGBA {
+	dst := imaging.Clone(base)
+	top := imaging.Clone(overlay)
+
+	for y := 0; y < m.Rect.Dy(); y++ {
+		for x := 0; x < m.Rect.Dx(); x++ {
+			a := int(m.Pix[y*m.Stride+x])
+			if a == 0 {
+				continue
+			}
+
+			i := y*dst.Stride + x*4
+			j := y*top.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8((int(dst.Pix[i+c])*(255-a) + int(top.Pix[j+c])*a + 127) / 255)
+			}
+		}
+	}
+
+	return dst
+}
+
+// Bounds returns the smallest rectangle that contains every set pixel of m.
+func Bounds(m *image.Alpha) image.Rectangle {
+	var r image.Rectangle
+	for y := m.Rect.Min.Y; y < m.Rect.Max.Y; y++ {
+		row := m.Pix[(y-m.Rect.Min.Y)*m.Stride : (y-m.Rect.Min.Y)*m.Stride+m.Rect.Dx()]
+		for x, a := range row {
+			if a != 0 {
+				r = r.Union(image.Rect(m.Rect.Min.X+x, y, m.Rect.Min.X+x+1, y+1))
+			}
+		}
+	}
+
+	return r
+}
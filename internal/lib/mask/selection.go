@@ -0,0 +1,153 @@
+package mask
+
+import (
+	"fmt"
+	"image"
+	"online-photo-editor/internal/lib/colors"
+
+	"github.com/disintegration/imaging"
+)
+
+// Mask selects the part of an image an action applies to. Exactly one of the
+// region shapes, MaskName or ColorRange must be set.
+type Mask struct {
+	Region
+	MaskName   string      `json:"mask_name" validate:"omitempty,max=100"`
+	ColorRange *ColorRange `json:"color_range"`
+	Feather    float64     `json:"feather" validate:"min=0,max=100"`
+	Invert     bool        `json:"invert"`
+}
+
+// ColorRange selects pixels by hue in degrees and by saturation and value in
+// percent. A nil range matches everything; a hue range with Min > Max wraps
+// around red.
+type ColorRange struct {
+	Hue        *HueRange `json:"hue"`
+	Saturation *Range    `json:"saturation"`
+	Value      *Range    `json:"value"`
+}
+
+type HueRange struct {
+	Min float64 `json:"min" validate:"min=0,max=360"`
+	Max float64 `json:"max" validate:"min=0,max=360"`
+}
+
+type Range struct {
+	Min float64 `json:"min" validate:"min=0,max=100"`
+	Max float64 `json:"max" validate:"min=0,max=100"`
+}
+
+// Build renders the selection over img. stored is the image named by
+// MaskName and is ignored otherwise; its luminance times its alpha is the
+// selection, stretched to the size of img if needed.
+func (m *Mask) Build(img image.Image, stored image.Image) (*image.Alpha, error) {
+	const op = "lib.mask.Build"
+
+	sources := m.Region.shapes()
+	if m.MaskName != "" {
+		sources++
+	}
+	if m.ColorRange != nil {
+		sources++
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("%s: mask must have exactly one of rect, ellipse, polygon, mask_name or color_range", op)
+	}
+
+	bounds := image.Rect(0, 0, img.Bounds().Dx(), img.Bounds().Dy())
+
+	var alpha *image.Alpha
+	switch {
+	case m.MaskName != "":
+		if stored == nil {
+			return nil, fmt.Errorf("%s: mask image %s is missing", op, m.MaskName)
+		}
+		alpha = Gray(stored, bounds)
+	case m.ColorRange != nil:
+		alpha = m.ColorRange.Select(img)
+	default:
+		var err error
+		if alpha, err = Regions([]Region{m.Region}, bounds); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if m.Feather > 0 {
+		alpha = Feather(alpha, m.Feather)
+	}
+	if m.Invert {
+		for i, a := range alpha.Pix {
+			alpha.Pix[i] = 255 - a
+		}
+	}
+
+	return alpha, nil
+}
+
+// Gray converts a stored mask image into a mask that covers bounds.
+func Gray(img image.Image, bounds image.Rectangle) *image.Alpha {
+	src := imaging.Clone(img)
+	if src.Rect.Dx() != bounds.Dx() || src.Rect.Dy() != bounds.Dy() {
+		src = imaging.Resize(src, bounds.Dx(), bounds.Dy(), imaging.Linear)
+	}
+
+	m := image.NewAlpha(bounds)
+	for i := range m.Pix {
+		p := src.Pix[i*4 : i*4+4]
+		luma := colors.Luma(float64(p[0]), float64(p[1]), float64(p[2]))
+		m.Pix[i] = colors.Clamp8(luma * float64(p[3]) / 255)
+	}
+
+	return m
+}
+
+// Feather softens the edges of m with a Gaussian blur of the given sigma.
+func Feather(m *image.Alpha, sigma float64) *image.Alpha {
+	blurred := imaging.Blur(m, sigma)
+
+	dst := image.NewAlpha(m.Rect)
+	for i := range dst.Pix {
+		dst.Pix[i] = blurred.Pix[i*4+3]
+	}
+
+	return dst
+}
+
+// Select returns a mask that is fully set where the color of img falls into
+// every range of c.
+func (c *ColorRange) Select(img image.Image) *image.Alpha {
+	src := imaging.Clone(img)
+
+	m := image.NewAlpha(src.Rect)
+	for i := range m.Pix {
+		p := src.Pix[i*4 : i*4+4]
+		if p[3] != 0 && c.Contains(float64(p[0])/255, float64(p[1])/255, float64(p[2])/255) {
+			m.Pix[i] = 255
+		}
+	}
+
+	return m
+}
+
+// Contains reports whether a color with components in [0, 1] is selected.
+func (c *ColorRange) Contains(r, g, b float64) bool {
+	h, s, v := colors.RGBToHSV(r, g, b)
+
+	if c.Hue != nil {
+		if c.Hue.Min <= c.Hue.Max {
+			if h < c.Hue.Min || h > c.Hue.Max {
+				return false
+			}
+		} else if h < c.Hue.Min && h > c.Hue.Max {
+			return false
+		}
+	}
+	if c.Saturation != nil && (s*100 < c.Saturation.Min || s*100 > c.Saturation.Max) {
+		return false
+	}
+	if c.Value != nil && (v*100 < c.Value.Min || v*100 > c.Value.Max) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,77 @@
+package mask
+
+import (
+	"image"
+
+	"github.com/disintegration/imaging"
+)
+
+// MagicWand selects the pixels whose largest per-channel difference from the
+// color at seed is at most limit (0 to 255). With contiguous set only pixels
+// connected to the seed through selected pixels are included.
+func MagicWand(img image.Image, seed image.Point, limit float64, contiguous bool) *image.Alpha {
+	src := imaging.Clone(img)
+	w, h := src.Rect.Dx(), src.Rect.Dy()
+
+	ref := src.Pix[seed.Y*src.Stride+seed.X*4 : seed.Y*src.Stride+seed.X*4+4]
+	match := func(i int) bool {
+		p := src.Pix[i*4 : i*4+4]
+		for c := range p {
+			d := int(p[c]) - int(ref[c])
+			if float64(max(d, -d)) > limit {
+				return false
+			}
+		}
+		return true
+	}
+
+	m := image.NewAlpha(src.Rect)
+	if !contiguous {
+		for i := range m.Pix {
+			if match(i) {
+				m.Pix[i] = 255
+			}
+		}
+		return m
+	}
+
+	// Scanline flood fill: every popped pixel is extended to its full run on
+	// the row, and the rows above and below are queued once per run.
+	stack := []image.Point{seed}
+	for len(stack) > 0 {
+		p := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		row := p.Y * w
+		if m.Pix[row+p.X] != 0 || !match(row+p.X) {
+			continue
+		}
+
+		x0, x1 := p.X, p.X
+		for x0 > 0 && m.Pix[row+x0-1] == 0 && match(row+x0-1) {
+			x0--
+		}
+		for x1 < w-1 && m.Pix[row+x1+1] == 0 && match(row+x1+1) {
+			x1++
+		}
+
+		for x := x0; x <= x1; x++ {
+			m.Pix[row+x] = 255
+		}
+		for _, y := range [2]int{p.Y - 1, p.Y + 1} {
+			if y < 0 || y >= h {
+				continue
+			}
+			inRun := false
+			for x := x0; x <= x1; x++ {
+				open := m.Pix[y*w+x] == 0 && match(y*w+x)
+				if open && !inRun {
+					stack = append(stack, image.Point{X: x, Y: y})
+				}
+				inRun = open
+			}
+		}
+	}
+
+	return m
+}
@@ -0,0 +1,94 @@
+package mask_test
+
+import (
+	"image"
+	"image/color"
+	"math/rand"
+	"online-photo-editor/internal/lib/mask"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	white = color.NRGBA{R: 255, G: 255, B: 255, A: 255}
+	red   = color.NRGBA{R: 255, A: 255}
+)
+
+// ring is a white image with a red square outline from (2,2) to (7,7).
+func ring() *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10))
+	for y := 0; y < 10; y++ {
+		for x := 0; x < 10; x++ {
+			c := white
+			if (x == 2 || x == 7) && y >= 2 && y <= 7 || (y == 2 || y == 7) && x >= 2 && x <= 7 {
+				c = red
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestMagicWand_ContiguousStopsAtBoundary(t *testing.T) {
+	m := mask.MagicWand(ring(), image.Pt(0, 0), 10, true)
+
+	assert.Equal(t, uint8(255), m.AlphaAt(9, 9).A)
+	assert.Equal(t, uint8(255), m.AlphaAt(0, 5).A)
+	assert.Equal(t, uint8(0), m.AlphaAt(2, 2).A, "the outline has another color")
+	assert.Equal(t, uint8(0), m.AlphaAt(4, 4).A, "the inside is not connected")
+}
+
+func TestMagicWand_GlobalSelectsAllMatches(t *testing.T) {
+	m := mask.MagicWand(ring(), image.Pt(0, 0), 10, false)
+
+	assert.Equal(t, uint8(255), m.AlphaAt(4, 4).A)
+	assert.Equal(t, uint8(0), m.AlphaAt(7, 5).A)
+}
+
+func TestMagicWand_Tolerance(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 3, 1))
+	img.SetNRGBA(0, 0, color.NRGBA{R: 100, A: 255})
+	img.SetNRGBA(1, 0, color.NRGBA{R: 120, A: 255})
+	img.SetNRGBA(2, 0, color.NRGBA{R: 100, A: 255})
+
+	assert.Equal(t, []uint8{255, 0, 0}, mask.MagicWand(img, image.Pt(0, 0), 19, true).Pix)
+	assert.Equal(t, []uint8{255, 0, 255}, mask.MagicWand(img, image.Pt(0, 0), 19, false).Pix)
+	assert.Equal(t, []uint8{255, 255, 255}, mask.MagicWand(img, image.Pt(0, 0), 20, true).Pix)
+}
+
+// TestMagicWand_MatchesFloodFill compares the scanline fill with a plain
+// four-way flood fill on random two-color images full of winding regions.
+func TestMagicWand_MatchesFloodFill(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	const w, h = 30, 20
+
+	for n := 0; n < 50; n++ {
+		img := image.NewNRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				c := white
+				if rng.Intn(5) < 2 {
+					c = red
+				}
+				img.SetNRGBA(x, y, c)
+			}
+		}
+		seed := image.Pt(rng.Intn(w), rng.Intn(h))
+
+		want := make([]uint8, w*h)
+		stack := []image.Point{seed}
+		for len(stack) > 0 {
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if !p.In(img.Rect) || want[p.Y*w+p.X] != 0 || img.NRGBAAt(p.X, p.Y) != img.NRGBAAt(seed.X, seed.Y) {
+				continue
+			}
+			want[p.Y*w+p.X] = 255
+			stack = append(stack, p.Add(image.Pt(1, 0)), p.Add(image.Pt(-1, 0)), p.Add(image.Pt(0, 1)), p.Add(image.Pt(0, -1)))
+		}
+
+		assert.Equal(t, want, mask.MagicWand(img, seed, 0, true).Pix, "image %d seed %v", n, seed)
+	}
+}
@@ -0,0 +1,32 @@
+package parallel
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Rows calls fn for every row in [0, n), spread over the available CPUs. fn
+// must only write to data that belongs to its row.
+func Rows(n int, fn func(y int)) {
+	workers := min(runtime.GOMAXPROCS(0), n)
+	if workers <= 1 {
+		for y := 0; y < n; y++ {
+			fn(y)
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for y := int(next.Add(1)) - 1; y < n; y = int(next.Add(1)) - 1 {
+				fn(y)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -1,20 +1,20 @@
 package filesystem
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"image"
 	"image/gif"
-	"image/jpeg"
-	"image/png"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"online-photo-editor/internal/lib/cube"
+	"online-photo-editor/internal/lib/format"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
-
-	"golang.org/x/image/bmp"
 )
 
 type ImageStorage struct {
@@ -35,19 +35,20 @@ func (img *ImageStorage) UploadImage(file multipart.File, handler *multipart.Fil
 	const op = "storage.img.UploadImage"
 
 	buffer := make([]byte, 512)
-	if _, err := file.Read(buffer); err != nil {
+	n, err := file.Read(buffer)
+	if err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 	if _, err := file.Seek(0, io.SeekStart); err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	mimeType := http.DetectContentType(buffer)
-	if !isImage(mimeType) {
-		return "", fmt.Errorf("%s: unsupported file type: %s", op, mimeType)
+	imgFormat, ok := format.Sniff(buffer[:n])
+	if !ok {
+		return "", fmt.Errorf("%s: unsupported file type: %s", op, http.DetectContentType(buffer[:n]))
 	}
 
-	fileName, err := img.GenerateName("img", filepath.Ext(handler.Filename))
+	fileName, err := img.GenerateName("img", imgFormat.Extension)
 	if err != nil {
 		return "", err
 	}
@@ -112,7 +113,18 @@ func (img *ImageStorage) LoadImage(imgName string) (image.Image, error) {
 	}
 	defer file.Close()
 
-	loadImg, _, err := image.Decode(file)
+	reader := bufio.NewReader(file)
+	header, err := reader.Peek(16)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	imgFormat, ok := format.Sniff(header)
+	if !ok {
+		return nil, fmt.Errorf("%s: unsupported file type", op)
+	}
+
+	loadImg, err := imgFormat.Decode(reader)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -126,90 +138,122 @@ func (img *ImageStorage) SaveImage(inputImg image.Image, imgName string) (string
 	filePath := filepath.Join(img.Path, imgName)
 
 	fileExt := strings.ToLower(filepath.Ext(imgName))
-	var err error
-
-	switch fileExt {
-	case ".jpg", ".jpeg":
-		err = saveJPEG(inputImg, filePath)
-	case ".png":
-		err = savePNG(inputImg, filePath)
-	case ".gif":
-		err = saveGIF(inputImg, filePath)
-	case ".bmp":
-		err = saveBMP(inputImg, filePath)
-	default:
+
+	imgFormat, ok := format.Lookup(fileExt)
+	if !ok || !imgFormat.CanEncode() {
 		return "", fmt.Errorf("%s: unsupported file format: %s", op, fileExt)
 	}
+
+	file, err := os.Create(filePath)
 	if err != nil {
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
+	defer file.Close()
+
+	if err := imgFormat.Encode(file, inputImg); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
 
 	imageURL := fmt.Sprintf("/images/%s", imgName)
 
 	return imageURL, nil
 }
 
-func (img *ImageStorage) GenerateName(prefix string, fileExt string) (string, error) {
-	const op = "storage.img.GenerateName"
+func (img *ImageStorage) SaveAnimation(anim *gif.GIF, imgName string) (string, error) {
+	const op = "storage.img.SaveAnimation"
 
-	if prefix == "" || fileExt == "" {
-		return "", fmt.Errorf("%s: the file prefix or extension must not be empty", op)
+	if fileExt := strings.ToLower(filepath.Ext(imgName)); fileExt != ".gif" {
+		return "", fmt.Errorf("%s: unsupported file format: %s", op, fileExt)
 	}
 
-	if !strings.HasPrefix(fileExt, ".") {
-		fileExt = "." + fileExt
+	file, err := os.Create(filepath.Join(img.Path, imgName))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
+	defer file.Close()
 
-	return fmt.Sprintf("%s_%s%s", prefix, time.Now().Format("20060102150405"), fileExt), nil
+	if err := gif.EncodeAll(file, anim); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	imageURL := fmt.Sprintf("/images/%s", imgName)
+
+	return imageURL, nil
 }
 
-func saveJPEG(img image.Image, filePath string) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return err
+func (img *ImageStorage) UploadLUT(file multipart.File, handler *multipart.FileHeader) (string, error) {
+	const op = "storage.img.UploadLUT"
+
+	if fileExt := strings.ToLower(filepath.Ext(handler.Filename)); fileExt != ".cube" {
+		return "", fmt.Errorf("%s: unsupported file type: %s", op, fileExt)
 	}
-	defer file.Close()
 
-	return jpeg.Encode(file, img, nil)
-}
+	if _, err := cube.Parse(file); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
 
-func savePNG(img image.Image, filePath string) error {
-	file, err := os.Create(filePath)
+	fileName, err := img.GenerateName("lut", ".cube")
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer file.Close()
 
-	return png.Encode(file, img)
-}
-
-func saveGIF(img image.Image, filePath string) error {
-	file, err := os.Create(filePath)
+	dst, err := os.Create(filepath.Join(img.Path, fileName))
 	if err != nil {
-		return err
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
-	defer file.Close()
+	defer dst.Close()
 
-	return gif.Encode(file, img, nil)
+	if _, err = io.Copy(dst, file); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	lutURL := fmt.Sprintf("/images/%s", fileName)
+
+	return lutURL, nil
 }
 
-func saveBMP(img image.Image, filePath string) error {
-	file, err := os.Create(filePath)
+func (img *ImageStorage) LoadLUT(lutName string) (*cube.LUT, error) {
+	const op = "storage.img.LoadLUT"
+
+	if fileExt := strings.ToLower(filepath.Ext(lutName)); fileExt != ".cube" {
+		return nil, fmt.Errorf("%s: unsupported file type: %s", op, fileExt)
+	}
+
+	filepath := filepath.Join(img.Path, lutName)
+
+	if err := checkFile(filepath); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	file, err := os.Open(filepath)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 	defer file.Close()
 
-	return bmp.Encode(file, img)
+	lut, err := cube.Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return lut, nil
 }
 
-func isImage(mimeType string) bool {
-	switch mimeType {
-	case "image/jpeg", "image/png", "image/bmp", "image/gif":
-		return true
-	default:
-		return false
+func (img *ImageStorage) GenerateName(prefix string, fileExt string) (string, error) {
+	const op = "storage.img.GenerateName"
+
+	if prefix == "" || fileExt == "" {
+		return "", fmt.Errorf("%s: the file prefix or extension must not be empty", op)
+	}
+
+	if !strings.HasPrefix(fileExt, ".") {
+		fileExt = "." + fileExt
 	}
+
+	return fmt.Sprintf("%s_%s%s", prefix, time.Now().Format("20060102150405"), fileExt), nil
 }
 
 func checkFile(filePath string) error {